@@ -0,0 +1,149 @@
+package filter
+
+import (
+	"time"
+
+	sq "github.com/n-r-w/squirrel"
+)
+
+// Cond condition for filtering. Conditions are created by Gt, Between, IsNull, And, Or, etc.
+// and added to the filter with WithConds.
+type Cond interface {
+	// toSqlizer returns the condition. Returns false if the condition is empty and must be skipped.
+	toSqlizer() (sq.Sqlizer, bool)
+}
+
+// exprCond condition rendered by squirrel.
+type exprCond struct {
+	expr sq.Sqlizer
+}
+
+func (c exprCond) toSqlizer() (sq.Sqlizer, bool) {
+	return c.expr, c.expr != nil
+}
+
+// groupCond AND/OR group of conditions.
+type groupCond struct {
+	conds []Cond
+	or    bool
+}
+
+func (c groupCond) toSqlizer() (sq.Sqlizer, bool) {
+	sqlizers := make([]sq.Sqlizer, 0, len(c.conds))
+	for _, cond := range c.conds {
+		s, ok := cond.toSqlizer()
+		if !ok {
+			if c.or {
+				// an empty condition is always true, so the whole OR group is always true
+				return nil, false
+			}
+			continue
+		}
+		sqlizers = append(sqlizers, s)
+	}
+
+	switch {
+	case len(sqlizers) == 0:
+		return nil, false
+	case len(sqlizers) == 1:
+		return sqlizers[0], true
+	case c.or:
+		return sq.Or(sqlizers), true
+	default:
+		return sq.And(sqlizers), true
+	}
+}
+
+// Gt returns condition sqlName > value.
+func Gt(sqlName string, value any) Cond {
+	return exprCond{expr: sq.Gt{sqlName: value}}
+}
+
+// Gte returns condition sqlName >= value.
+func Gte(sqlName string, value any) Cond {
+	return exprCond{expr: sq.GtOrEq{sqlName: value}}
+}
+
+// Lt returns condition sqlName < value.
+func Lt(sqlName string, value any) Cond {
+	return exprCond{expr: sq.Lt{sqlName: value}}
+}
+
+// Lte returns condition sqlName <= value.
+func Lte(sqlName string, value any) Cond {
+	return exprCond{expr: sq.LtOrEq{sqlName: value}}
+}
+
+// Between returns condition sqlName BETWEEN from AND to. Both bounds are inclusive.
+func Between(sqlName string, from, to any) Cond {
+	return exprCond{expr: sq.Expr(sqlName+" BETWEEN ? AND ?", from, to)}
+}
+
+// NotIn returns condition sqlName NOT IN (values). Empty values are skipped.
+func NotIn[T any](sqlName string, values []T) Cond {
+	if len(values) == 0 {
+		return exprCond{expr: nil}
+	}
+
+	anyValues := make([]any, 0, len(values))
+	for _, v := range values {
+		anyValues = append(anyValues, v)
+	}
+
+	return exprCond{expr: sq.NotEq{sqlName: anyValues}}
+}
+
+// IsNull returns condition sqlName IS NULL.
+func IsNull(sqlName string) Cond {
+	return exprCond{expr: sq.Eq{sqlName: nil}}
+}
+
+// IsNotNull returns condition sqlName IS NOT NULL.
+func IsNotNull(sqlName string) Cond {
+	return exprCond{expr: sq.NotEq{sqlName: nil}}
+}
+
+// TimeRange returns condition for the half-open interval from <= sqlName < to.
+// A zero bound is not limited. If both bounds are zero, the condition is skipped.
+func TimeRange(sqlName string, from, to time.Time) Cond {
+	conds := make([]Cond, 0, 2) //nolint:mnd // 2 bounds
+	if !from.IsZero() {
+		conds = append(conds, Gte(sqlName, from))
+	}
+	if !to.IsZero() {
+		conds = append(conds, Lt(sqlName, to))
+	}
+
+	return And(conds...)
+}
+
+// ArrayContains returns condition sqlName @> values: the array column contains all values.
+// Empty values are skipped.
+func ArrayContains[T any](sqlName string, values []T) Cond {
+	if len(values) == 0 {
+		return exprCond{expr: nil}
+	}
+
+	return exprCond{expr: sq.Expr(sqlName+" @> ?", values)}
+}
+
+// ArrayOverlaps returns condition sqlName && values: the array column contains at least one of the values.
+// Empty values are skipped.
+func ArrayOverlaps[T any](sqlName string, values []T) Cond {
+	if len(values) == 0 {
+		return exprCond{expr: nil}
+	}
+
+	return exprCond{expr: sq.Expr(sqlName+" && ?", values)}
+}
+
+// And combines conditions with AND. Empty conditions are skipped.
+func And(conds ...Cond) Cond {
+	return groupCond{conds: conds, or: false}
+}
+
+// Or combines conditions with OR. An empty condition matches everything,
+// so the whole group is skipped if any of its conditions is empty.
+func Or(conds ...Cond) Cond {
+	return groupCond{conds: conds, or: true}
+}
@@ -0,0 +1,86 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupCond(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		cond     Cond
+		wantSQL  string
+		wantArgs []any
+		wantOK   bool
+	}{
+		{
+			name:   "empty and",
+			cond:   And(),
+			wantOK: false,
+		},
+		{
+			name:   "empty or",
+			cond:   Or(),
+			wantOK: false,
+		},
+		{
+			name:     "and skips empty conditions",
+			cond:     And(Gt("price", 10), NotIn("id", []int{}), IsNull("deleted_at")),
+			wantSQL:  "(price > ? AND deleted_at IS NULL)",
+			wantArgs: []any{10},
+			wantOK:   true,
+		},
+		{
+			name:     "and with one condition",
+			cond:     And(NotIn("id", []int{}), Gt("price", 10)),
+			wantSQL:  "price > ?",
+			wantArgs: []any{10},
+			wantOK:   true,
+		},
+		{
+			name:     "or",
+			cond:     Or(Gt("price", 10), IsNull("deleted_at")),
+			wantSQL:  "(price > ? OR deleted_at IS NULL)",
+			wantArgs: []any{10},
+			wantOK:   true,
+		},
+		{
+			name:   "or with empty condition",
+			cond:   Or(Gt("price", 10), NotIn("id", []int{})),
+			wantOK: false,
+		},
+		{
+			name:   "or with empty group",
+			cond:   Or(Gt("price", 10), And()),
+			wantOK: false,
+		},
+		{
+			name:     "and with always true or",
+			cond:     And(Gt("price", 10), Or(IsNull("deleted_at"), NotIn("id", []int{}))),
+			wantSQL:  "price > ?",
+			wantArgs: []any{10},
+			wantOK:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, ok := tt.cond.toSqlizer()
+			require.Equal(t, tt.wantOK, ok)
+			if !ok {
+				require.Nil(t, s)
+				return
+			}
+
+			sql, args, err := s.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantSQL, sql)
+			require.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	sq "github.com/n-r-w/squirrel"
+)
+
+// Cursor position in a result set for keyset pagination.
+// Contains the values of the sort columns of a boundary row: sort conditions from WithOrders
+// in the order they were added, followed by PK columns if they are not among the sort conditions.
+type Cursor struct {
+	// Values of the sort columns, not NULL. Supported types are strings, booleans, integers, floats, time.Time
+	// and 16-byte arrays such as uuid.UUID. Types are kept in the token: integers are decoded as int64,
+	// floats as float64 and 16-byte arrays as [16]byte, other types are decoded as is.
+	Values []any
+	// Backward true for reading the page before the boundary row.
+	Backward bool
+}
+
+// cursorToken JSON representation of Cursor. JSON doesn't keep types of values, so they are stored separately.
+type cursorToken struct {
+	Values   []any    `json:"v"`
+	Types    []string `json:"t"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+// Types of cursor values.
+const (
+	cursorTypeString = "s"
+	cursorTypeInt    = "i"
+	cursorTypeFloat  = "f"
+	cursorTypeBool   = "b"
+	cursorTypeTime   = "t"
+	cursorTypeUUID   = "u"
+)
+
+// Encode returns opaque cursor token. Returns ErrUnsupportedCursorValue if a value has an unsupported type.
+func (c Cursor) Encode() (string, error) {
+	token := cursorToken{
+		Values:   make([]any, len(c.Values)),
+		Types:    make([]string, len(c.Values)),
+		Backward: c.Backward,
+	}
+
+	for i, v := range c.Values {
+		value, typ, err := encodeCursorValue(v)
+		if err != nil {
+			return "", fmt.Errorf("encode cursor: %w", err)
+		}
+		token.Values[i], token.Types[i] = value, typ
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes cursor token created by Cursor.Encode.
+func DecodeCursor(token string) (Cursor, error) {
+	var (
+		c Cursor
+		t cursorToken
+	)
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&t); err != nil {
+		return c, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if len(t.Values) == 0 || len(t.Values) != len(t.Types) {
+		return c, ErrInvalidCursor
+	}
+
+	c.Values = make([]any, len(t.Values))
+	c.Backward = t.Backward
+
+	for i, v := range t.Values {
+		if c.Values[i], err = decodeCursorValue(v, t.Types[i]); err != nil {
+			return Cursor{}, err
+		}
+	}
+
+	return c, nil
+}
+
+// encodeCursorValue returns the JSON value and the type of a cursor value.
+func encodeCursorValue(v any) (any, string, error) {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339Nano), cursorTypeTime, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() { //nolint:exhaustive // other kinds are not supported
+	case reflect.String:
+		return rv.String(), cursorTypeString, nil
+	case reflect.Bool:
+		return rv.Bool(), cursorTypeBool, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), cursorTypeInt, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return nil, "", fmt.Errorf("%w: %d overflows int64", ErrUnsupportedCursorValue, rv.Uint())
+		}
+		return int64(rv.Uint()), cursorTypeInt, nil //nolint:gosec // checked above
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), cursorTypeFloat, nil
+	case reflect.Array:
+		if rv.Type().ConvertibleTo(uuidType) {
+			return formatUUID(rv.Convert(uuidType).Interface().([16]byte)), cursorTypeUUID, nil //nolint:forcetypeassert // converted
+		}
+	}
+
+	return nil, "", fmt.Errorf("%w: %T", ErrUnsupportedCursorValue, v)
+}
+
+// decodeCursorValue converts the JSON value of a cursor to the value of its type.
+func decodeCursorValue(v any, typ string) (any, error) {
+	var err error
+
+	switch typ {
+	case cursorTypeString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case cursorTypeBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case cursorTypeInt:
+		if num, ok := v.(json.Number); ok {
+			var n int64
+			if n, err = num.Int64(); err == nil {
+				return n, nil
+			}
+		}
+	case cursorTypeFloat:
+		if num, ok := v.(json.Number); ok {
+			var f float64
+			if f, err = num.Float64(); err == nil {
+				return f, nil
+			}
+		}
+	case cursorTypeTime:
+		if s, ok := v.(string); ok {
+			var t time.Time
+			if t, err = time.Parse(time.RFC3339Nano, s); err == nil {
+				return t, nil
+			}
+		}
+	case cursorTypeUUID:
+		if s, ok := v.(string); ok {
+			var u [16]byte
+			if u, err = parseUUID(s); err == nil {
+				return u, nil
+			}
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	return nil, fmt.Errorf("%w: invalid value %v of type %q", ErrInvalidCursor, v, typ)
+}
+
+// uuidType type of UUID values in cursors.
+var uuidType = reflect.TypeFor[[16]byte]() //nolint:gochecknoglobals // immutable
+
+// formatUUID formats UUID in the canonical form: xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx.
+func formatUUID(u [16]byte) string {
+	s := hex.EncodeToString(u[:])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// parseUUID parses UUID in the canonical form.
+func parseUUID(s string) ([16]byte, error) {
+	var u [16]byte
+
+	const uuidLen = 36
+	if len(s) != uuidLen || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("invalid UUID %q", s)
+	}
+
+	if _, err := hex.Decode(u[:], []byte(strings.ReplaceAll(s, "-", ""))); err != nil {
+		return u, fmt.Errorf("invalid UUID %q: %w", s, err)
+	}
+
+	return u, nil
+}
+
+// NewCursorPaginator returns a keyset paginator. An empty token means the first page.
+// Rows are sorted by the sort conditions from WithOrders with PK as a tiebreaker,
+// and filtered by row comparison with the cursor values.
+// The token is obtained from CursorResult of the previous request.
+func NewCursorPaginator(limit uint32, token string) (*Paginator, error) {
+	p := &Paginator{
+		limit:         limit,
+		lastID:        nil,
+		paginatorType: cursorPaginatorType,
+		offset:        0,
+		cursor:        nil,
+	}
+
+	if token == "" {
+		return p, nil
+	}
+
+	c, err := DecodeCursor(token)
+	if err != nil {
+		return nil, err
+	}
+	p.cursor = &c
+
+	return p, nil
+}
+
+// CursorResult converts rows selected with a cursor paginator to the page in display order
+// and returns tokens of the next and previous pages. An empty token means there is no such page.
+// The next page is assumed to exist if the page is full, so the last page can be empty.
+// The values function returns the values of the sort columns of a row, see Cursor.
+func CursorResult[T any](p *Paginator, rows []T, values func(row T) []any) (page []T, next, prev string, err error) {
+	if !p.IsCursor() {
+		return nil, "", "", ErrNotCursorPaginator
+	}
+
+	backward := p.cursor != nil && p.cursor.Backward
+	full := p.limit > 0 && len(rows) >= int(p.limit)
+
+	page = rows
+	if backward {
+		// rows of the backward page are selected in reverse order
+		page = slices.Clone(rows)
+		slices.Reverse(page)
+	}
+
+	if len(page) == 0 {
+		// nothing beyond the boundary row
+		return page, "", "", nil
+	}
+
+	hasNext := full || backward
+	hasPrev := p.cursor != nil && (!backward || full)
+
+	if hasNext {
+		if next, err = (Cursor{Values: values(page[len(page)-1]), Backward: false}).Encode(); err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	if hasPrev {
+		if prev, err = (Cursor{Values: values(page[0]), Backward: true}).Encode(); err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	return page, next, prev, nil
+}
+
+// sortColumn column of the keyset.
+type sortColumn struct {
+	name string
+	desc bool
+}
+
+// orderBy returns ORDER BY expression. If reverse is true, the direction is inverted.
+func (c sortColumn) orderBy(reverse bool) string {
+	if c.desc != reverse {
+		return c.name + " " + DESC.String()
+	}
+	return c.name + " " + ASC.String()
+}
+
+// pkColumns splits PK field into columns. Composite key is specified in the format: (field1, field2).
+func pkColumns(pkField string) []string {
+	pkField = strings.TrimSpace(pkField)
+	pkField = strings.TrimPrefix(pkField, "(")
+	pkField = strings.TrimSuffix(pkField, ")")
+
+	fields := strings.Split(pkField, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	return fields
+}
+
+// keysetCondition returns the condition for rows after (or before for backward) the cursor.
+// If all columns have the same direction, row comparison is used, which can use a composite index.
+// Otherwise, the condition is expanded: (a > $1) OR (a = $1 AND b < $2) OR ...
+func keysetCondition(columns []sortColumn, c *Cursor) (sq.Sqlizer, error) {
+	if len(columns) != len(c.Values) {
+		return nil, fmt.Errorf("%w: expected %d values, got %d", ErrInvalidCursor, len(columns), len(c.Values))
+	}
+
+	operator := func(desc bool) string {
+		if desc != c.Backward {
+			return "<"
+		}
+		return ">"
+	}
+
+	sameDirection := !slices.ContainsFunc(columns, func(col sortColumn) bool {
+		return col.desc != columns[0].desc
+	})
+
+	if sameDirection {
+		names := make([]string, 0, len(columns))
+		for _, col := range columns {
+			names = append(names, col.name)
+		}
+
+		if len(columns) == 1 {
+			return sq.Expr(fmt.Sprintf("%s %s ?", names[0], operator(columns[0].desc)), c.Values...), nil
+		}
+
+		return sq.Expr(fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(names, ", "), operator(columns[0].desc), sq.Placeholders(len(columns))), c.Values...), nil
+	}
+
+	or := sq.Or{}
+	for i, col := range columns {
+		cond := sq.Expr(fmt.Sprintf("%s %s ?", col.name, operator(col.desc)), c.Values[i])
+		if i == 0 {
+			or = append(or, cond)
+			continue
+		}
+
+		and := sq.And{}
+		for j := range i {
+			and = append(and, sq.Expr(columns[j].name+" = ?", c.Values[j]))
+		}
+		or = append(or, append(and, cond))
+	}
+
+	return or, nil
+}
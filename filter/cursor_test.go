@@ -0,0 +1,125 @@
+package filter
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	token, err := Cursor{Values: []any{"name", 42, 1.5, true}, Backward: true}.Encode()
+	require.NoError(t, err)
+
+	c, err := DecodeCursor(token)
+	require.NoError(t, err)
+	require.Equal(t, Cursor{Values: []any{"name", int64(42), 1.5, true}, Backward: true}, c)
+
+	for _, invalid := range []string{"!!!", "bm90IGpzb24", "e30"} { // not base64, not json, no values
+		_, err = DecodeCursor(invalid)
+		require.ErrorIs(t, err, ErrInvalidCursor, invalid)
+	}
+
+	// value doesn't match its type
+	_, err = DecodeCursor(base64.RawURLEncoding.EncodeToString([]byte(`{"v":["abc"],"t":["i"]}`)))
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestCursor_Types(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	created := time.Date(2024, 5, 1, 10, 30, 0, 123456000, time.FixedZone("", 3*60*60))
+
+	type status string
+
+	token, err := Cursor{Values: []any{id, created, status("new"), uint16(7)}, Backward: false}.Encode()
+	require.NoError(t, err)
+
+	c, err := DecodeCursor(token)
+	require.NoError(t, err)
+	require.Len(t, c.Values, 4)
+	require.Equal(t, [16]byte(id), c.Values[0])
+	decodedTime, ok := c.Values[1].(time.Time)
+	require.True(t, ok)
+	require.True(t, created.Equal(decodedTime))
+	require.Equal(t, "new", c.Values[2])
+	require.Equal(t, int64(7), c.Values[3])
+
+	// unsupported types are rejected when the cursor is built
+	for _, v := range []any{nil, []int{1}, struct{}{}, [8]byte{}} {
+		_, err = Cursor{Values: []any{v}, Backward: false}.Encode()
+		require.ErrorIs(t, err, ErrUnsupportedCursorValue)
+	}
+}
+
+func TestCursorResult(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID int64
+	}
+
+	values := func(r row) []any { return []any{r.ID} }
+
+	decode := func(token string) Cursor {
+		c, err := DecodeCursor(token)
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("First page", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := NewCursorPaginator(2, "")
+		require.NoError(t, err)
+
+		page, next, prev, err := CursorResult(p, []row{{1}, {2}}, values)
+		require.NoError(t, err)
+		require.Equal(t, []row{{1}, {2}}, page)
+		require.Empty(t, prev)
+		require.Equal(t, Cursor{Values: []any{int64(2)}, Backward: false}, decode(next))
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		t.Parallel()
+
+		token, err := Cursor{Values: []any{2}}.Encode() //nolint:exhaustruct // test
+		require.NoError(t, err)
+		p, err := NewCursorPaginator(2, token)
+		require.NoError(t, err)
+
+		page, next, prev, err := CursorResult(p, []row{{3}}, values)
+		require.NoError(t, err)
+		require.Equal(t, []row{{3}}, page)
+		require.Empty(t, next)
+		require.Equal(t, Cursor{Values: []any{int64(3)}, Backward: true}, decode(prev))
+	})
+
+	t.Run("Backward page", func(t *testing.T) {
+		t.Parallel()
+
+		token, err := Cursor{Values: []any{5}, Backward: true}.Encode()
+		require.NoError(t, err)
+		p, err := NewCursorPaginator(2, token)
+		require.NoError(t, err)
+
+		// rows are selected in reverse order
+		page, next, prev, err := CursorResult(p, []row{{4}, {3}}, values)
+		require.NoError(t, err)
+		require.Equal(t, []row{{3}, {4}}, page)
+		require.Equal(t, Cursor{Values: []any{int64(4)}, Backward: false}, decode(next))
+		require.Equal(t, Cursor{Values: []any{int64(3)}, Backward: true}, decode(prev))
+	})
+
+	t.Run("Not cursor paginator", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, _, err := CursorResult(NewFrontPaginator(2, 1), []row{{1}}, values)
+		require.ErrorIs(t, err, ErrNotCursorPaginator)
+	})
+}
@@ -10,4 +10,15 @@ var (
 
 	// ErrUnknownOrderType unknown sort type.
 	ErrUnknownOrderType = errors.New("unknown order type")
+
+	// ErrInvalidCursor cursor token is malformed or doesn't match the sort conditions.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrUnsupportedCursorValue cursor value has a type that can't be encoded, see Cursor.
+	ErrUnsupportedCursorValue = errors.New("unsupported cursor value")
+
+	// ErrNotCursorPaginator paginator is not a cursor paginator.
+	ErrNotCursorPaginator = errors.New("not a cursor paginator")
+
+	// ErrCursorWithoutOrder cursor paginator is used together with WithoutOrder.
+	ErrCursorWithoutOrder = errors.New("cursor paginator requires sorting")
 )
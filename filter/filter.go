@@ -12,6 +12,9 @@ import (
 
 type option func(f *filter)
 
+// Option filter option. Exported alias for passing options between packages, e.g. from httpquery.
+type Option = option
+
 // WithPKField specifies the name for PK. Used in cases where PK name differs
 // from default "id". Can specify a composite key in the format: (field1, field2).
 func WithPKField(pkfield string) option { //nolint:revive,lll //unexported-return is intentional for functional options pattern
@@ -35,6 +38,14 @@ func WithInConds(conds ...inCond) option { //nolint:revive //unexported-return i
 	}
 }
 
+// WithConds adds conditions created by Gt, Between, IsNull, And, Or, etc.
+// Conditions are combined with AND. Empty conditions are skipped.
+func WithConds(conds ...Cond) option { //nolint:revive //unexported-return is intentional
+	return func(f *filter) {
+		f.conds = append(f.conds, conds...)
+	}
+}
+
 // WithOrders adds sorting conditions (orders). Aliases for mapping orderID from
 // OrderCond and sqlName are passed in aliases parameter. Duplicate sort conditions are ignored.
 func WithOrders(aliases map[int]string, orders ...OrderCond) option { //nolint:revive,lll //unexported-return is intentional
@@ -67,9 +78,18 @@ func WithSearch(search string, fields ...string) option { //nolint:revive //unex
 	}
 }
 
+// WithSearchStrategy sets the search strategy of WithSearch. Default is LikeSearch.
+// Ranking of the strategy is ignored with the cursor paginator, as it can't be used as a keyset.
+func WithSearchStrategy(strategy SearchStrategy) option { //nolint:revive //unexported-return is intentional
+	return func(f *filter) {
+		f.searchStrategy = strategy
+	}
+}
+
 // WithPaginator adds pagination based on Paginator. If a service paginator is
 // provided, pagination will be performed by the last PK value with condition pkField > lastID.
 // If a frontend paginator is provided, pagination will be performed by limit and offset.
+// If a cursor paginator is provided, pagination will be performed by row comparison with the cursor values.
 // Panics when attempting to add paginator again.
 func WithPaginator(paginator Paginator) option { //nolint:revive //unexported-return is intentional
 	return func(f *filter) {
@@ -82,14 +102,16 @@ func WithPaginator(paginator Paginator) option { //nolint:revive //unexported-re
 
 type filter struct {
 	inConds []inCond
+	conds   []Cond
 
 	orders       []OrderCond
 	orderAliases map[int]string
 	withoutOrder bool
 
-	search       string
-	searchFields []string
-	paginator    Paginator
+	search         string
+	searchFields   []string
+	searchStrategy SearchStrategy
+	paginator      Paginator
 
 	// PK field. Defaults to "id". When no explicit sorting is provided, sorts by this field.
 	// Can use a composite key, for example, (product_id, repository_id).
@@ -98,14 +120,16 @@ type filter struct {
 
 func newFilter(opts ...option) (*filter, error) {
 	f := &filter{
-		pkField:      "id",
-		inConds:      nil,
-		orders:       nil,
-		orderAliases: nil,
-		withoutOrder: false,
-		search:       "",
-		searchFields: nil,
-		paginator:    Paginator{limit: 0, offset: 0, lastID: nil, paginatorType: emptyPaginatorType},
+		pkField:        "id",
+		inConds:        nil,
+		conds:          nil,
+		orders:         nil,
+		orderAliases:   nil,
+		withoutOrder:   false,
+		search:         "",
+		searchFields:   nil,
+		searchStrategy: LikeSearch{},
+		paginator:      Paginator{limit: 0, offset: 0, lastID: nil, paginatorType: emptyPaginatorType, cursor: nil},
 	}
 
 	for _, o := range opts {
@@ -137,3 +161,24 @@ func NewSelectBuilder(opts ...option) (sq.SelectBuilder, error) {
 
 	return newSquirrelBuilder(f).build()
 }
+
+// NewCountBuilder returns squirrel SelectBuilder for counting rows matching provided opts.
+// Conditions are the same as in NewSelectBuilder, but sorting and pagination are ignored.
+// The builder already contains COUNT(*) column, only FROM and joins need to be added.
+func NewCountBuilder(opts ...option) (sq.SelectBuilder, error) {
+	f, err := newFilter(opts...)
+	if err != nil {
+		return sq.Select(), err
+	}
+
+	f.orders = nil
+	f.withoutOrder = true
+	f.paginator = Paginator{limit: 0, offset: 0, lastID: nil, paginatorType: emptyPaginatorType, cursor: nil}
+
+	builder, err := newSquirrelBuilder(f).build()
+	if err != nil {
+		return builder, err
+	}
+
+	return builder.Columns("COUNT(*)"), nil
+}
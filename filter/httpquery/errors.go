@@ -0,0 +1,27 @@
+package httpquery
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidQuery query string doesn't match the schema.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// FieldError validation error of a query parameter.
+type FieldError struct {
+	// Param query parameter, e.g. "price[gte]".
+	Param string
+	// Message description of the error.
+	Message string
+}
+
+// Error implements error.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Param, e.Message)
+}
+
+// Unwrap returns ErrInvalidQuery.
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidQuery
+}
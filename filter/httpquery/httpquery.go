@@ -0,0 +1,284 @@
+// Package httpquery converts HTTP query strings to filter options.
+// Only fields described in Schema are accepted, so user input never reaches SQL as identifiers.
+package httpquery
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/pgh/v2/filter"
+)
+
+// Parse converts query parameters to filter options, for example:
+//
+//	?sort=-name&name=a,b&price[gte]=10&q=foo&page=2&limit=20
+//
+// All validation errors are returned together, each of them is *FieldError and matches ErrInvalidQuery.
+func (s *Schema) Parse(values url.Values) ([]filter.Option, error) {
+	p := &parser{schema: s, opts: nil, errs: nil}
+
+	// sorted keys make options and errors deterministic
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var conds []filter.Cond
+	for _, key := range keys {
+		switch key {
+		case SortParam, SearchParam, PageParam, LimitParam, CursorParam:
+			continue
+		}
+
+		conds = append(conds, p.parseCond(key, values[key])...)
+	}
+	if len(conds) > 0 {
+		p.opts = append(p.opts, filter.WithConds(conds...))
+	}
+
+	p.parseSort(values.Get(SortParam))
+	p.parseSearch(values.Get(SearchParam))
+	p.parsePaginator(values.Get(PageParam), values.Get(LimitParam), values.Get(CursorParam))
+
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+
+	return p.opts, nil
+}
+
+type parser struct {
+	schema *Schema
+	opts   []filter.Option
+	errs   []error
+}
+
+func (p *parser) addError(param, format string, args ...any) {
+	p.errs = append(p.errs, &FieldError{Param: param, Message: fmt.Sprintf(format, args...)})
+}
+
+// parseCond parses name=value or name[op]=value.
+func (p *parser) parseCond(key string, rawValues []string) []filter.Cond {
+	name, op := key, OpEq
+	if idx := strings.IndexByte(key, '['); idx > 0 && strings.HasSuffix(key, "]") {
+		name, op = key[:idx], Operator(key[idx+1:len(key)-1])
+	}
+
+	field, ok := p.schema.Fields[name]
+	if !ok {
+		if !p.schema.AllowUnknown {
+			p.addError(key, "unknown parameter")
+		}
+		return nil
+	}
+
+	if !field.allows(op) {
+		p.addError(key, "operator %q is not allowed", op)
+		return nil
+	}
+
+	var raw []string
+	for _, v := range rawValues {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				raw = append(raw, part)
+			}
+		}
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+
+	if op == OpNull {
+		isNull, err := strconv.ParseBool(raw[0])
+		if err != nil || len(raw) > 1 {
+			p.addError(key, "expected true or false")
+			return nil
+		}
+		if isNull {
+			return []filter.Cond{filter.IsNull(field.Column)}
+		}
+		return []filter.Cond{filter.IsNotNull(field.Column)}
+	}
+
+	parsed := make([]any, 0, len(raw))
+	for _, v := range raw {
+		value, err := parseValue(field.Type, v)
+		if err != nil {
+			p.addError(key, "invalid value %q: %v", v, err)
+			return nil
+		}
+		parsed = append(parsed, value)
+	}
+
+	switch op {
+	case OpEq:
+		return []filter.Cond{filter.NewInCond(field.Column, parsed)}
+	case OpNe:
+		return []filter.Cond{filter.NotIn(field.Column, parsed)}
+	case OpGt, OpGte, OpLt, OpLte:
+		if len(parsed) > 1 {
+			p.addError(key, "expected a single value")
+			return nil
+		}
+		return []filter.Cond{rangeCond(op, field.Column, parsed[0])}
+	case OpNull: // handled above
+	}
+
+	p.addError(key, "unknown operator %q", op)
+	return nil
+}
+
+func rangeCond(op Operator, column string, value any) filter.Cond {
+	switch op {
+	case OpGt:
+		return filter.Gt(column, value)
+	case OpGte:
+		return filter.Gte(column, value)
+	case OpLt:
+		return filter.Lt(column, value)
+	default:
+		return filter.Lte(column, value)
+	}
+}
+
+func parseValue(t FieldType, v string) (any, error) {
+	switch t {
+	case Int:
+		return strconv.ParseInt(v, 10, 64)
+	case Float:
+		return strconv.ParseFloat(v, 64)
+	case Bool:
+		return strconv.ParseBool(v)
+	case Time:
+		if tm, err := time.Parse(time.RFC3339, v); err == nil {
+			return tm, nil
+		}
+		return time.Parse(time.DateOnly, v)
+	case String:
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// parseSort parses sort=-name,created_at.
+func (p *parser) parseSort(value string) {
+	if value == "" {
+		return
+	}
+
+	var (
+		aliases = map[int]string{}
+		orders  []filter.OrderCond
+	)
+
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		orderType := filter.ASC
+		if after, ok := strings.CutPrefix(name, "-"); ok {
+			name, orderType = after, filter.DESC
+		}
+
+		field, ok := p.schema.Fields[name]
+		if !ok || !field.Sortable {
+			p.addError(SortParam, "field %q is not sortable", name)
+			continue
+		}
+
+		orderID := len(orders)
+		aliases[orderID] = field.Column
+		orders = append(orders, *filter.NewOrder(orderID, orderType))
+	}
+
+	if len(orders) > 0 {
+		p.opts = append(p.opts, filter.WithOrders(aliases, orders...))
+	}
+}
+
+// parseSearch parses q=text.
+func (p *parser) parseSearch(value string) {
+	if value == "" {
+		return
+	}
+
+	var columns []string
+	for _, field := range p.schema.Fields {
+		if field.Searchable {
+			columns = append(columns, field.Column)
+		}
+	}
+
+	if len(columns) == 0 {
+		p.addError(SearchParam, "search is not supported")
+		return
+	}
+
+	slices.Sort(columns)
+	p.opts = append(p.opts, filter.WithSearch(value, columns...))
+
+	if p.schema.SearchStrategy != nil {
+		p.opts = append(p.opts, filter.WithSearchStrategy(p.schema.SearchStrategy))
+	}
+}
+
+// parsePaginator parses page, limit and cursor.
+func (p *parser) parsePaginator(page, limit, cursor string) {
+	size := p.schema.DefaultLimit
+	if limit != "" {
+		v, err := strconv.ParseUint(limit, 10, 32)
+		if err != nil || v == 0 {
+			p.addError(LimitParam, "expected a positive number")
+			return
+		}
+		size = uint32(v)
+	}
+
+	if p.schema.MaxLimit > 0 && (size == 0 || size > p.schema.MaxLimit) {
+		if limit != "" {
+			p.addError(LimitParam, "maximum is %d", p.schema.MaxLimit)
+			return
+		}
+		size = p.schema.MaxLimit
+	}
+
+	if cursor != "" {
+		if page != "" {
+			p.addError(CursorParam, "can't be used together with %s", PageParam)
+			return
+		}
+
+		paginator, err := filter.NewCursorPaginator(size, cursor)
+		if err != nil {
+			p.addError(CursorParam, "invalid cursor")
+			return
+		}
+		p.opts = append(p.opts, filter.WithPaginator(*paginator))
+		return
+	}
+
+	pageNum := uint64(1)
+	if page != "" {
+		v, err := strconv.ParseUint(page, 10, 64)
+		if err != nil || v == 0 {
+			p.addError(PageParam, "expected a positive number")
+			return
+		}
+		pageNum = v
+	}
+
+	if size == 0 {
+		if page != "" {
+			p.addError(PageParam, "%s is required", LimitParam)
+		}
+		return
+	}
+
+	p.opts = append(p.opts, filter.WithPaginator(*filter.NewFrontPaginator(size, pageNum)))
+}
@@ -0,0 +1,130 @@
+package httpquery
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/n-r-w/pgh/v2/filter"
+	"github.com/stretchr/testify/require"
+)
+
+func testSchema() *Schema {
+	return &Schema{
+		Fields: map[string]Field{
+			"name": {
+				Column:     "u.name",
+				Type:       String,
+				Operators:  []Operator{OpEq, OpNe},
+				Sortable:   true,
+				Searchable: true,
+			},
+			"age": {
+				Column:     "u.age",
+				Type:       Int,
+				Operators:  []Operator{OpEq, OpGte, OpLt},
+				Sortable:   false,
+				Searchable: false,
+			},
+			"deleted": {
+				Column:     "u.deleted_at",
+				Type:       Time,
+				Operators:  []Operator{OpNull},
+				Sortable:   false,
+				Searchable: false,
+			},
+			"created": {
+				Column:     "u.created_at",
+				Type:       Time,
+				Operators:  nil,
+				Sortable:   true,
+				Searchable: false,
+			},
+		},
+		DefaultLimit:   10,
+		MaxLimit:       100,
+		SearchStrategy: nil,
+		AllowUnknown:   false,
+	}
+}
+
+func buildSQL(t *testing.T, opts []filter.Option) (string, []any) {
+	t.Helper()
+
+	builder, err := filter.NewSelectBuilder(opts...)
+	require.NoError(t, err)
+
+	sql, args, err := builder.Columns("*").From("users u").ToSql()
+	require.NoError(t, err)
+
+	return strings.Join(strings.Fields(sql), " "), args
+}
+
+func TestSchema_Parse(t *testing.T) {
+	t.Parallel()
+
+	values, err := url.ParseQuery(
+		"sort=-name,created&name=a,b&name[ne]=c&age[gte]=18&deleted[null]=true&q=foo&page=2&limit=20")
+	require.NoError(t, err)
+
+	opts, err := testSchema().Parse(values)
+	require.NoError(t, err)
+
+	sql, args := buildSQL(t, opts)
+	require.Equal(t, "SELECT * FROM users u "+
+		"WHERE u.age >= $1 AND u.deleted_at IS NULL AND u.name IN ($2,$3) AND u.name NOT IN ($4) "+
+		"AND (u.name::text LIKE $5) "+
+		"ORDER BY u.name DESC, u.created_at ASC LIMIT 20 OFFSET 20", sql)
+	require.Equal(t, []any{int64(18), "a", "b", "c", "%foo%"}, args)
+}
+
+func TestSchema_ParseDefaults(t *testing.T) {
+	t.Parallel()
+
+	opts, err := testSchema().Parse(url.Values{})
+	require.NoError(t, err)
+
+	sql, _ := buildSQL(t, opts)
+	require.Equal(t, "SELECT * FROM users u ORDER BY id ASC LIMIT 10", sql)
+}
+
+func TestSchema_ParseCursor(t *testing.T) {
+	t.Parallel()
+
+	token, err := filter.Cursor{Values: []any{"bob", 5}, Backward: false}.Encode()
+	require.NoError(t, err)
+
+	opts, err := testSchema().Parse(url.Values{SortParam: {"name"}, CursorParam: {token}})
+	require.NoError(t, err)
+
+	sql, args := buildSQL(t, opts)
+	require.Equal(t, "SELECT * FROM users u WHERE (u.name, id) > ($1,$2) ORDER BY u.name ASC, id ASC LIMIT 10", sql)
+	require.Equal(t, []any{"bob", int64(5)}, args)
+}
+
+func TestSchema_ParseErrors(t *testing.T) {
+	t.Parallel()
+
+	values, err := url.ParseQuery("sort=age&password=x&age[gt]=1&age=abc&limit=1000&q=foo&name[null]=true")
+	require.NoError(t, err)
+
+	schema := testSchema()
+	delete(schema.Fields, "name") // no searchable fields
+
+	_, err = schema.Parse(values)
+	require.ErrorIs(t, err, ErrInvalidQuery)
+
+	var params []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() { //nolint:errorlint,forcetypeassert // test
+		var fieldErr *FieldError
+		require.ErrorAs(t, e, &fieldErr)
+		params = append(params, fieldErr.Param)
+	}
+
+	require.Equal(t, []string{"age", "age[gt]", "name[null]", "password", SortParam, SearchParam, LimitParam}, params)
+
+	// unknown parameters can be ignored
+	schema.AllowUnknown = true
+	_, err = schema.Parse(url.Values{"password": {"x"}})
+	require.NoError(t, err)
+}
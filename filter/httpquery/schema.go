@@ -0,0 +1,96 @@
+package httpquery
+
+import "github.com/n-r-w/pgh/v2/filter"
+
+// FieldType type of a field value in the query string.
+type FieldType int
+
+const (
+	// String value is passed as is.
+	String FieldType = iota
+	// Int value is parsed as int64.
+	Int
+	// Float value is parsed as float64.
+	Float
+	// Bool value is parsed by strconv.ParseBool.
+	Bool
+	// Time value is parsed as RFC 3339 or as a date in the format 2006-01-02.
+	Time
+)
+
+// Operator comparison operator of a field, passed as name[op]=value.
+type Operator string
+
+const (
+	// OpEq name=a,b or name[eq]=a,b: the column is one of the values.
+	OpEq Operator = "eq"
+	// OpNe name[ne]=a,b: the column is none of the values.
+	OpNe Operator = "ne"
+	// OpGt name[gt]=value.
+	OpGt Operator = "gt"
+	// OpGte name[gte]=value.
+	OpGte Operator = "gte"
+	// OpLt name[lt]=value.
+	OpLt Operator = "lt"
+	// OpLte name[lte]=value.
+	OpLte Operator = "lte"
+	// OpNull name[null]=true for IS NULL, name[null]=false for IS NOT NULL.
+	OpNull Operator = "null"
+)
+
+// Reserved query parameters.
+const (
+	// SortParam sort=-name,created_at: sorting by sortable fields, "-" means descending order.
+	SortParam = "sort"
+	// SearchParam q=text: search by searchable fields.
+	SearchParam = "q"
+	// PageParam page=2: page number starting from 1.
+	PageParam = "page"
+	// LimitParam limit=20: page size.
+	LimitParam = "limit"
+	// CursorParam cursor=token: keyset pagination token, see filter.NewCursorPaginator.
+	CursorParam = "cursor"
+)
+
+// Field field allowed in the query string.
+type Field struct {
+	// Column SQL column name. Never comes from user input.
+	Column string
+	// Type type of the value.
+	Type FieldType
+	// Operators allowed operators. If empty, only OpEq is allowed.
+	Operators []Operator
+	// Sortable the field can be used in the sort parameter.
+	Sortable bool
+	// Searchable the field is used by the search parameter.
+	Searchable bool
+}
+
+// Schema whitelist of fields available in the query string.
+type Schema struct {
+	// Fields by API name.
+	Fields map[string]Field
+	// DefaultLimit page size if the limit parameter is not passed. 0 means no pagination by default.
+	DefaultLimit uint32
+	// MaxLimit maximum page size. 0 means no limit.
+	MaxLimit uint32
+	// SearchStrategy strategy of the search parameter. If nil, filter.LikeSearch is used.
+	SearchStrategy filter.SearchStrategy
+	// AllowUnknown ignores parameters that are not in the schema instead of returning an error.
+	AllowUnknown bool
+}
+
+// allows returns true if the operator is allowed for the field.
+func (f Field) allows(op Operator) bool {
+	if len(f.Operators) == 0 {
+		return op == OpEq
+	}
+
+	for _, o := range f.Operators {
+		if o == op {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,5 +1,11 @@
 package filter
 
+import (
+	"reflect"
+
+	sq "github.com/n-r-w/squirrel"
+)
+
 // inCond represents an IN condition in SQL.
 type inCond struct {
 	sqlName      string
@@ -29,3 +35,27 @@ func (i inCond) WithZeroValue(use bool) inCond {
 	i.useZeroValue = use
 	return i
 }
+
+// toSqlizer returns IN condition. Implements Cond, so inCond can be used in And/Or groups.
+func (i inCond) toSqlizer() (sq.Sqlizer, bool) {
+	if len(i.values) == 0 {
+		return nil, false
+	}
+
+	values := []any{}
+	if i.useZeroValue {
+		values = i.values
+	} else {
+		for _, v := range i.values {
+			if !reflect.ValueOf(v).IsZero() {
+				values = append(values, v)
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	return sq.Eq{i.sqlName: values}, true
+}
@@ -6,6 +6,7 @@ const (
 	emptyPaginatorType = paginatorType(iota)
 	frontPaginatorType
 	servicePaginatorType
+	cursorPaginatorType
 )
 
 // Paginator Data pagination.
@@ -16,6 +17,9 @@ type Paginator struct {
 	// Value of the last received id
 	lastID        any
 	paginatorType paginatorType
+
+	// Cursor of the keyset paginator. Nil for the first page.
+	cursor *Cursor
 }
 
 // NewFrontPaginator returns a frontend type paginator.
@@ -25,6 +29,7 @@ func NewFrontPaginator(limit uint32, page uint64) *Paginator {
 		lastID:        nil,
 		paginatorType: frontPaginatorType,
 		offset:        (page - 1) * uint64(limit),
+		cursor:        nil,
 	}
 }
 
@@ -35,6 +40,7 @@ func NewServicePaginator(limit uint32, lastID uint64) *Paginator {
 		lastID:        lastID,
 		paginatorType: servicePaginatorType,
 		offset:        0,
+		cursor:        nil,
 	}
 }
 
@@ -48,6 +54,21 @@ func (p *Paginator) IsService() bool {
 	return p.paginatorType == servicePaginatorType
 }
 
+// Limit returns the page size. 0 means no limit.
+func (p *Paginator) Limit() uint32 {
+	return p.limit
+}
+
+// Offset returns the number of rows to skip. Always 0 for service and cursor paginators.
+func (p *Paginator) Offset() uint64 {
+	return p.offset
+}
+
+// IsCursor returns true if paginator type is cursor (keyset).
+func (p *Paginator) IsCursor() bool {
+	return p.paginatorType == cursorPaginatorType
+}
+
 func (p *Paginator) isEmpty() bool {
 	return p.paginatorType == emptyPaginatorType
 }
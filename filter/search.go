@@ -0,0 +1,177 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	sq "github.com/n-r-w/squirrel"
+)
+
+// SearchStrategy renders the search condition of WithSearch.
+type SearchStrategy interface {
+	// Where returns the search condition for fields. Returns nil if there is no condition.
+	Where(search string, fields []string) sq.Sqlizer
+	// OrderBy returns the ranking expression for sorting by relevance. Returns nil if ranking is not used.
+	OrderBy(search string, fields []string) sq.Sqlizer
+}
+
+// LikeSearch case-sensitive substring search: field::text LIKE '%search%'.
+// Wildcards in the search string are not escaped. Default strategy, kept for compatibility.
+type LikeSearch struct{}
+
+// Where implements SearchStrategy.
+func (LikeSearch) Where(search string, fields []string) sq.Sqlizer {
+	or := sq.Or{}
+	for _, field := range fields {
+		or = append(or, sq.Like{field + "::text": "%" + search + "%"})
+	}
+
+	return or
+}
+
+// OrderBy implements SearchStrategy.
+func (LikeSearch) OrderBy(string, []string) sq.Sqlizer {
+	return nil
+}
+
+// ILikeSearch case-insensitive substring search: field::text ILIKE '%search%'.
+// Wildcards % and _ in the search string are escaped.
+type ILikeSearch struct{}
+
+// Where implements SearchStrategy.
+func (ILikeSearch) Where(search string, fields []string) sq.Sqlizer {
+	or := sq.Or{}
+	for _, field := range fields {
+		or = append(or, sq.ILike{field + "::text": "%" + EscapeLike(search) + "%"})
+	}
+
+	return or
+}
+
+// OrderBy implements SearchStrategy.
+func (ILikeSearch) OrderBy(string, []string) sq.Sqlizer {
+	return nil
+}
+
+// PrefixSearch prefix search: field::text LIKE 'search%'. Wildcards in the search string are escaped.
+// A case-sensitive search can use a btree index with text_pattern_ops.
+type PrefixSearch struct {
+	// CaseInsensitive uses ILIKE instead of LIKE.
+	CaseInsensitive bool
+}
+
+// Where implements SearchStrategy.
+func (s PrefixSearch) Where(search string, fields []string) sq.Sqlizer {
+	or := sq.Or{}
+	for _, field := range fields {
+		if s.CaseInsensitive {
+			or = append(or, sq.ILike{field + "::text": EscapeLike(search) + "%"})
+		} else {
+			or = append(or, sq.Like{field + "::text": EscapeLike(search) + "%"})
+		}
+	}
+
+	return or
+}
+
+// OrderBy implements SearchStrategy.
+func (PrefixSearch) OrderBy(string, []string) sq.Sqlizer {
+	return nil
+}
+
+// TrigramSearch similarity search using the pg_trgm extension.
+type TrigramSearch struct {
+	// Threshold minimum similarity from 0 to 1. If 0, the % operator is used, which can use
+	// a GIN/GiST trigram index and the pg_trgm.similarity_threshold setting (0.3 by default).
+	Threshold float64
+	// Rank sorts rows by the best similarity in descending order. Ignored with WithoutOrder and in count queries.
+	Rank bool
+}
+
+// Where implements SearchStrategy.
+func (s TrigramSearch) Where(search string, fields []string) sq.Sqlizer {
+	or := sq.Or{}
+	for _, field := range fields {
+		if s.Threshold > 0 {
+			or = append(or, sq.Expr(fmt.Sprintf("similarity(%s::text, ?) >= ?", field), search, s.Threshold))
+		} else {
+			or = append(or, sq.Expr(field+"::text % ?", search))
+		}
+	}
+
+	return or
+}
+
+// OrderBy implements SearchStrategy.
+func (s TrigramSearch) OrderBy(search string, fields []string) sq.Sqlizer {
+	if !s.Rank {
+		return nil
+	}
+
+	parts := make([]string, 0, len(fields))
+	args := make([]any, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, fmt.Sprintf("similarity(%s::text, ?)", field))
+		args = append(args, search)
+	}
+
+	if len(parts) == 1 {
+		return sq.Expr(parts[0]+" DESC", args...)
+	}
+
+	return sq.Expr("GREATEST("+strings.Join(parts, ", ")+") DESC", args...)
+}
+
+// FullTextSearch full-text search with websearch_to_tsquery, which supports quotes, OR and -exclusion.
+type FullTextSearch struct {
+	// Config text search configuration, e.g. "english". Default is "simple".
+	Config string
+	// Vector fields are tsvector columns. Otherwise, fields are concatenated and converted with to_tsvector,
+	// and an expression index on the same expression is needed.
+	Vector bool
+	// Rank sorts rows by ts_rank in descending order. Ignored with WithoutOrder and in count queries.
+	Rank bool
+}
+
+// Where implements SearchStrategy.
+func (s FullTextSearch) Where(search string, fields []string) sq.Sqlizer {
+	vector, args := s.vector(fields)
+	return sq.Expr(vector+" @@ websearch_to_tsquery(?::regconfig, ?)", append(args, s.config(), search)...)
+}
+
+// OrderBy implements SearchStrategy.
+func (s FullTextSearch) OrderBy(search string, fields []string) sq.Sqlizer {
+	if !s.Rank {
+		return nil
+	}
+
+	vector, args := s.vector(fields)
+	return sq.Expr("ts_rank("+vector+", websearch_to_tsquery(?::regconfig, ?)) DESC",
+		append(args, s.config(), search)...)
+}
+
+func (s FullTextSearch) config() string {
+	if s.Config == "" {
+		return "simple"
+	}
+	return s.Config
+}
+
+// vector returns tsvector expression for fields.
+func (s FullTextSearch) vector(fields []string) (string, []any) {
+	if s.Vector {
+		return "(" + strings.Join(fields, " || ") + ")", nil
+	}
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, fmt.Sprintf("coalesce(%s::text, '')", field))
+	}
+
+	return "to_tsvector(?::regconfig, " + strings.Join(parts, " || ' ' || ") + ")", []any{s.config()}
+}
+
+// EscapeLike escapes LIKE wildcards % and _ and the escape character \ in s.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -2,7 +2,7 @@ package filter
 
 import (
 	"fmt"
-	"reflect"
+	"slices"
 
 	"github.com/n-r-w/pgh/v2"
 	sq "github.com/n-r-w/squirrel"
@@ -21,54 +21,57 @@ func newSquirrelBuilder(f *filter) *squirrelBuilder {
 func (s *squirrelBuilder) build() (sq.SelectBuilder, error) {
 	builder := pgh.Builder().Select()
 
+	columns := make([]sortColumn, 0, len(s.f.orders))
 	for _, ord := range s.f.orders {
 		sql, ok := s.f.orderAliases[ord.orderID]
 		if !ok {
 			continue
 		}
 		switch ord.Type {
-		case ASC:
-			builder = builder.OrderBy(sql + " " + ASC.String())
-		case DESC:
-			builder = builder.OrderBy(sql + " " + DESC.String())
+		case ASC, DESC:
+			columns = append(columns, sortColumn{name: sql, desc: ord.Type == DESC})
 		default:
 			return builder, fmt.Errorf("order type '%d': %w", ord.Type, ErrUnknownOrderType)
 		}
 	}
 
-	if len(s.f.orders) == 0 && !s.f.withoutOrder {
-		builder = builder.OrderBy(s.f.pkField + " " + ASC.String())
-	}
+	if s.f.paginator.IsCursor() {
+		var err error
+		if builder, err = s.buildCursor(builder, columns); err != nil {
+			return builder, err
+		}
+	} else {
+		// relevance goes before other sorting
+		if s.f.search != "" && len(s.f.searchFields) != 0 && !s.f.withoutOrder {
+			if rank := s.f.searchStrategy.OrderBy(s.f.search, s.f.searchFields); rank != nil {
+				builder = builder.OrderByClause(rank)
+			}
+		}
 
-	for _, cond := range s.f.inConds {
-		if len(cond.values) == 0 {
-			continue
+		for _, col := range columns {
+			builder = builder.OrderBy(col.orderBy(false))
 		}
 
-		values := []any{}
-		if cond.useZeroValue {
-			values = cond.values
-		} else {
-			for _, v := range cond.values {
-				if !reflect.ValueOf(v).IsZero() {
-					values = append(values, v)
-				}
-			}
+		if len(s.f.orders) == 0 && !s.f.withoutOrder {
+			builder = builder.OrderBy(s.f.pkField + " " + ASC.String())
 		}
+	}
 
-		if len(values) != 0 {
-			builder = builder.Where(sq.Eq{cond.sqlName: values})
+	for _, cond := range s.f.inConds {
+		if sqlizer, ok := cond.toSqlizer(); ok {
+			builder = builder.Where(sqlizer)
 		}
 	}
 
-	if s.f.search != "" {
-		search := sq.Or{}
-		for _, v := range s.f.searchFields {
-			search = append(search, sq.Like{v + "::text": "%" + s.f.search + "%"})
+	for _, cond := range s.f.conds {
+		if sqlizer, ok := cond.toSqlizer(); ok {
+			builder = builder.Where(sqlizer)
 		}
+	}
 
-		if len(search) != 0 {
-			builder = builder.Where(search)
+	if s.f.search != "" && len(s.f.searchFields) != 0 {
+		if where := s.f.searchStrategy.Where(s.f.search, s.f.searchFields); where != nil {
+			builder = builder.Where(where)
 		}
 	}
 
@@ -76,6 +79,10 @@ func (s *squirrelBuilder) build() (sq.SelectBuilder, error) {
 		builder = builder.Limit(uint64(s.f.paginator.limit))
 	}
 
+	if s.f.paginator.IsCursor() {
+		return builder, nil
+	}
+
 	if s.f.paginator.IsService() {
 		builder = builder.Where(sq.Gt{s.f.pkField: s.f.paginator.lastID})
 	} else if s.f.paginator.offset > 0 {
@@ -84,3 +91,36 @@ func (s *squirrelBuilder) build() (sq.SelectBuilder, error) {
 
 	return builder, nil
 }
+
+// buildCursor adds sorting and keyset condition for the cursor paginator. PK columns are used as a tiebreaker.
+func (s *squirrelBuilder) buildCursor(builder sq.SelectBuilder, columns []sortColumn) (sq.SelectBuilder, error) {
+	if s.f.withoutOrder {
+		return builder, ErrCursorWithoutOrder
+	}
+
+	// PK follows the direction of the last sort column, so that row comparison can be used more often
+	pkDesc := len(columns) > 0 && columns[len(columns)-1].desc
+	for _, pk := range pkColumns(s.f.pkField) {
+		if !slices.ContainsFunc(columns, func(col sortColumn) bool { return col.name == pk }) {
+			columns = append(columns, sortColumn{name: pk, desc: pkDesc})
+		}
+	}
+
+	cursor := s.f.paginator.cursor
+	backward := cursor != nil && cursor.Backward
+
+	for _, col := range columns {
+		// the previous page is selected in reverse order
+		builder = builder.OrderBy(col.orderBy(backward))
+	}
+
+	if cursor != nil {
+		cond, err := keysetCondition(columns, cursor)
+		if err != nil {
+			return builder, err
+		}
+		builder = builder.Where(cond)
+	}
+
+	return builder, nil
+}
@@ -3,6 +3,7 @@ package filter
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -50,6 +51,175 @@ func (s *SquirrelBuilderSuite) TestBuild_filter() {
 	s.Require().Equal(expectedSQL, sql)
 }
 
+func (s *SquirrelBuilderSuite) TestBuild_conds() {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	sqBuilder, err := NewSelectBuilder(
+		WithIn("status", []string{"new"}),
+		WithConds(
+			Gt("price", 10),
+			Lte("price", 100),
+			Between("rating", 1, 5),
+			NotIn("id", []int{1, 2}),
+			NotIn("skipped", []int{}),
+			IsNull("deleted_at"),
+			TimeRange("created_at", from, to),
+			TimeRange("updated_at", time.Time{}, to),
+			TimeRange("skipped", time.Time{}, time.Time{}),
+			Or(
+				ArrayContains("tags", []string{"a", "b"}),
+				ArrayOverlaps("labels", []string{"c"}),
+				And(IsNotNull("parent_id"), Gte("level", 2), Lt("level", 4)),
+				*NewInCond("owner", []string{"bob"}),
+			),
+			Or(), // empty group is skipped
+		),
+	)
+	s.Require().NoError(err)
+
+	sql, vals, err := sqBuilder.Columns("*").From("table").ToSql()
+	s.Require().NoError(err)
+
+	s.Require().Equal([]any{
+		"new", 10, 100, 1, 5, 1, 2, from, to, to,
+		[]string{"a", "b"}, []string{"c"}, 2, 4, "bob",
+	}, vals)
+
+	expectedSQL := s.normalizeSQL(`
+		SELECT *
+		FROM table
+		WHERE status IN ($1)
+			AND price > $2
+			AND price <= $3
+			AND rating BETWEEN $4 AND $5
+			AND id NOT IN ($6,$7)
+			AND deleted_at IS NULL
+			AND (created_at >= $8 AND created_at < $9)
+			AND updated_at < $10
+			AND (tags @> $11 OR labels && $12
+				OR (parent_id IS NOT NULL AND level >= $13 AND level < $14)
+				OR owner IN ($15))
+		ORDER BY id ASC
+	`)
+	s.Require().Equal(expectedSQL, sql)
+}
+
+func (s *SquirrelBuilderSuite) TestBuild_count() {
+	const UserName = iota
+
+	paginator := NewFrontPaginator(10, 3)
+	s.Require().Equal(uint32(10), paginator.Limit())
+	s.Require().Equal(uint64(20), paginator.Offset())
+
+	opts := []option{
+		WithIn("status", []string{"new"}),
+		WithConds(Gt("price", 10)),
+		WithOrders(map[int]string{UserName: "name"}, *NewOrder(UserName, DESC)),
+		WithPaginator(*paginator),
+	}
+
+	sqBuilder, err := NewCountBuilder(opts...)
+	s.Require().NoError(err)
+
+	sql, vals, err := sqBuilder.From("table").ToSql()
+	s.Require().NoError(err)
+	s.Require().Equal([]any{"new", 10}, vals)
+
+	expectedSQL := s.normalizeSQL(`
+		SELECT COUNT(*)
+		FROM table
+		WHERE status IN ($1) AND price > $2
+	`)
+	s.Require().Equal(expectedSQL, sql)
+
+	// search rank is not added to the count query
+	sqBuilder, err = NewCountBuilder(
+		WithSearch("term", "title"),
+		WithSearchStrategy(TrigramSearch{Threshold: 0, Rank: true}),
+	)
+	s.Require().NoError(err)
+
+	sql, vals, err = sqBuilder.From("table").ToSql()
+	s.Require().NoError(err)
+	s.Require().Equal([]any{"term"}, vals)
+	s.Require().Equal(s.normalizeSQL(`SELECT COUNT(*) FROM table WHERE (title::text % $1)`), sql)
+}
+
+func (s *SquirrelBuilderSuite) TestBuild_searchStrategy() {
+	const search = `50%_off\`
+
+	tests := []struct {
+		name         string
+		strategy     SearchStrategy
+		expectedSQL  string
+		expectedVals []any
+	}{
+		{
+			name:     "ILIKE",
+			strategy: ILikeSearch{},
+			expectedSQL: `SELECT * FROM table WHERE (title::text ILIKE $1 OR body::text ILIKE $2)
+				ORDER BY id ASC`,
+			expectedVals: []any{`%50\%\_off\\%`, `%50\%\_off\\%`},
+		},
+		{
+			name:     "Prefix",
+			strategy: PrefixSearch{CaseInsensitive: false},
+			expectedSQL: `SELECT * FROM table WHERE (title::text LIKE $1 OR body::text LIKE $2)
+				ORDER BY id ASC`,
+			expectedVals: []any{`50\%\_off\\%`, `50\%\_off\\%`},
+		},
+		{
+			name:     "Trigram with rank",
+			strategy: TrigramSearch{Threshold: 0, Rank: true},
+			expectedSQL: `SELECT * FROM table WHERE (title::text % $1 OR body::text % $2)
+				ORDER BY GREATEST(similarity(title::text, $3), similarity(body::text, $4)) DESC, id ASC`,
+			expectedVals: []any{search, search, search, search},
+		},
+		{
+			name:     "Trigram with threshold",
+			strategy: TrigramSearch{Threshold: 0.5, Rank: false},
+			expectedSQL: `SELECT * FROM table
+				WHERE (similarity(title::text, $1) >= $2 OR similarity(body::text, $3) >= $4)
+				ORDER BY id ASC`,
+			expectedVals: []any{search, 0.5, search, 0.5},
+		},
+		{
+			name:     "Full-text with rank",
+			strategy: FullTextSearch{Config: "english", Vector: false, Rank: true},
+			expectedSQL: `SELECT * FROM table
+				WHERE to_tsvector($1::regconfig, coalesce(title::text, '') || ' ' || coalesce(body::text, ''))
+					@@ websearch_to_tsquery($2::regconfig, $3)
+				ORDER BY ts_rank(to_tsvector($4::regconfig,
+					coalesce(title::text, '') || ' ' || coalesce(body::text, '')),
+					websearch_to_tsquery($5::regconfig, $6)) DESC, id ASC`,
+			expectedVals: []any{"english", "english", search, "english", "english", search},
+		},
+		{
+			name:     "Full-text by tsvector columns",
+			strategy: FullTextSearch{Config: "", Vector: true, Rank: false},
+			expectedSQL: `SELECT * FROM table WHERE (title || body) @@ websearch_to_tsquery($1::regconfig, $2)
+				ORDER BY id ASC`,
+			expectedVals: []any{"simple", search},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			sqBuilder, err := NewSelectBuilder(
+				WithSearch(search, "title", "body"),
+				WithSearchStrategy(tt.strategy),
+			)
+			s.Require().NoError(err)
+
+			sql, vals, err := sqBuilder.Columns("*").From("table").ToSql()
+			s.Require().NoError(err)
+			s.Require().Equal(s.normalizeSQL(tt.expectedSQL), sql)
+			s.Require().Equal(tt.expectedVals, vals)
+		})
+	}
+}
+
 func (s *SquirrelBuilderSuite) TestBuild_order() {
 	s.Run("With explicit orders", func() {
 		const (
@@ -154,6 +324,123 @@ func (s *SquirrelBuilderSuite) TestBuild_paginator() {
 	})
 }
 
+func (s *SquirrelBuilderSuite) TestBuild_cursorPaginator() {
+	const (
+		UserName = iota
+		UserCreated
+	)
+
+	aliases := map[int]string{
+		UserName:    "name",
+		UserCreated: "created_at",
+	}
+
+	token := func(values []any, backward bool) string {
+		t, err := Cursor{Values: values, Backward: backward}.Encode()
+		s.Require().NoError(err)
+		return t
+	}
+
+	s.Run("First page", func() {
+		paginator, err := NewCursorPaginator(10, "")
+		s.Require().NoError(err)
+
+		sqBuilder, err := NewSelectBuilder(
+			WithOrders(aliases, *NewOrder(UserName, ASC)),
+			WithPaginator(*paginator),
+		)
+		s.Require().NoError(err)
+
+		sql, vals, err := sqBuilder.Columns("*").From("table").ToSql()
+		s.Require().NoError(err)
+		s.Require().Empty(vals)
+
+		expectedSQL := s.normalizeSQL(`
+			SELECT *
+			FROM table
+			ORDER BY name ASC, id ASC
+			LIMIT 10
+		`)
+		s.Require().Equal(expectedSQL, sql)
+	})
+
+	s.Run("Same direction uses row comparison", func() {
+		paginator, err := NewCursorPaginator(10, token([]any{"bob", 5}, false))
+		s.Require().NoError(err)
+
+		_, err = NewSelectBuilder(
+			WithOrders(aliases, *NewOrder(UserName, DESC)),
+			WithPKField("(user_id, org_id)"),
+			WithPaginator(*paginator),
+		)
+		s.Require().ErrorIs(err, ErrInvalidCursor) // 3 columns, but 2 values
+
+		paginator, err = NewCursorPaginator(10, token([]any{"bob", 5}, false))
+		s.Require().NoError(err)
+
+		sqBuilder, err := NewSelectBuilder(
+			WithOrders(aliases, *NewOrder(UserName, DESC)),
+			WithPKField("user_id"),
+			WithPaginator(*paginator),
+		)
+		s.Require().NoError(err)
+
+		sql, vals, err := sqBuilder.Columns("*").From("table").ToSql()
+		s.Require().NoError(err)
+		s.Require().Equal([]any{"bob", int64(5)}, vals)
+
+		expectedSQL := s.normalizeSQL(`
+			SELECT *
+			FROM table
+			WHERE (name, user_id) < ($1,$2)
+			ORDER BY name DESC, user_id DESC
+			LIMIT 10
+		`)
+		s.Require().Equal(expectedSQL, sql)
+	})
+
+	s.Run("Mixed directions backward", func() {
+		paginator, err := NewCursorPaginator(10, token([]any{"2024-01-01T00:00:00Z", "bob", 5}, true))
+		s.Require().NoError(err)
+
+		sqBuilder, err := NewSelectBuilder(
+			WithOrders(aliases, *NewOrder(UserCreated, DESC), *NewOrder(UserName, ASC)),
+			WithPaginator(*paginator),
+		)
+		s.Require().NoError(err)
+
+		sql, vals, err := sqBuilder.Columns("*").From("table").ToSql()
+		s.Require().NoError(err)
+		s.Require().Equal([]any{
+			"2024-01-01T00:00:00Z",
+			"2024-01-01T00:00:00Z", "bob",
+			"2024-01-01T00:00:00Z", "bob", int64(5),
+		}, vals)
+
+		expectedSQL := s.normalizeSQL(`
+			SELECT *
+			FROM table
+			WHERE (created_at > $1
+				OR (created_at = $2 AND name < $3)
+				OR (created_at = $4 AND name = $5 AND id < $6))
+			ORDER BY created_at ASC, name DESC, id DESC
+			LIMIT 10
+		`)
+		s.Require().Equal(expectedSQL, sql)
+	})
+
+	s.Run("Without order", func() {
+		paginator, err := NewCursorPaginator(10, "")
+		s.Require().NoError(err)
+
+		_, err = NewSelectBuilder(
+			WithoutOrder(),
+			WithPaginator(*paginator),
+		)
+		s.Require().ErrorIs(err, ErrCursorWithoutOrder)
+	})
+}
+
 func TestSquirrelBuilderSuite(t *testing.T) {
 	t.Parallel()
 	suite.Run(t, new(SquirrelBuilderSuite))
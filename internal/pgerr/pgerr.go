@@ -0,0 +1,196 @@
+// Package pgerr contains the query error type and classification of PostgreSQL errors shared by px and pq.
+// Both pgx and database/sql with the pgx stdlib driver return *pgconn.PgError for server errors.
+// https://www.postgresql.org/docs/16/errcodes-appendix.html
+package pgerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2"
+)
+
+// Error query error with the information about the query and the PostgreSQL error.
+type Error struct {
+	// Op operation, e.g. "sql select".
+	Op string
+	// SQL truncated query text.
+	SQL string
+	// Code SQLSTATE code. Empty if the error is not returned by the server.
+	Code string
+	// Class SQLSTATE class, the first two characters of Code.
+	Class string
+	// Schema name of the schema related to the error.
+	Schema string
+	// Table name of the table related to the error.
+	Table string
+	// Column name of the column related to the error.
+	Column string
+	// Constraint name of the constraint related to the error.
+	Constraint string
+	// Err original error.
+	Err error
+}
+
+// New creates Error for operation op with query sql. Fields are filled from *pgconn.PgError if err contains it.
+func New(op, sql string, err error) *Error {
+	e := &Error{ //nolint:exhaustruct // filled from PgError below
+		Op:  op,
+		SQL: pgh.TruncSQL(sql),
+		Err: err,
+	}
+
+	if pgErr, ok := AsPgError(err); ok {
+		e.Code = pgErr.Code
+		e.Class = codeClass(pgErr.Code)
+		e.Schema = pgErr.SchemaName
+		e.Table = pgErr.TableName
+		e.Column = pgErr.ColumnName
+		e.Constraint = pgErr.ConstraintName
+	}
+
+	return e
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	if e.SQL == "" {
+		return fmt.Sprintf("%s: %v", e.Op, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %v [%s]", e.Op, e.Err, e.SQL)
+}
+
+// Unwrap returns the original error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// AsError returns *Error from the error chain.
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// AsPgError returns *pgconn.PgError from the error chain.
+func AsPgError(err error) (*pgconn.PgError, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr, true
+	}
+	return nil, false
+}
+
+// Code returns the SQLSTATE code of the error or an empty string.
+func Code(err error) string {
+	if pgErr, ok := AsPgError(err); ok {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// Class returns the SQLSTATE class of the error or an empty string.
+func Class(err error) string {
+	return codeClass(Code(err))
+}
+
+// IsCode checks if the error has one of the SQLSTATE codes.
+func IsCode(err error, codes ...string) bool {
+	code := Code(err)
+	return code != "" && slices.Contains(codes, code)
+}
+
+// IsUniqueViolation checks if the error is a unique constraint violation.
+func IsUniqueViolation(err error) bool {
+	return IsCode(err, pgerrcode.UniqueViolation)
+}
+
+// IsForeignKeyViolation checks if the error is a foreign key constraint violation.
+func IsForeignKeyViolation(err error) bool {
+	return IsCode(err, pgerrcode.ForeignKeyViolation)
+}
+
+// IsNotNullViolation checks if the error is a not-null constraint violation.
+func IsNotNullViolation(err error) bool {
+	return IsCode(err, pgerrcode.NotNullViolation)
+}
+
+// IsCheckViolation checks if the error is a check constraint violation.
+func IsCheckViolation(err error) bool {
+	return IsCode(err, pgerrcode.CheckViolation)
+}
+
+// IsConflict checks if the error is caused by a conflict with existing data:
+// unique or exclusion constraint violation.
+func IsConflict(err error) bool {
+	return IsCode(err, pgerrcode.UniqueViolation, pgerrcode.ExclusionViolation)
+}
+
+// IsSerializationFailure checks if the error is a serialization failure.
+func IsSerializationFailure(err error) bool {
+	return IsCode(err, pgerrcode.SerializationFailure)
+}
+
+// IsDeadlock checks if the error is a deadlock detected error.
+func IsDeadlock(err error) bool {
+	return IsCode(err, pgerrcode.DeadlockDetected)
+}
+
+// IsRetryable checks if the transaction can be retried after the error: serialization failure or deadlock.
+func IsRetryable(err error) bool {
+	return IsSerializationFailure(err) || IsDeadlock(err)
+}
+
+// IsLockTimeout checks if a lock could not be acquired: lock_timeout expired or NOWAIT was used.
+func IsLockTimeout(err error) bool {
+	return IsCode(err, pgerrcode.LockNotAvailable)
+}
+
+// IsQueryCanceled checks if the query was canceled by the server: statement_timeout expired
+// or the query was canceled by the user or pg_cancel_backend.
+func IsQueryCanceled(err error) bool {
+	return IsCode(err, pgerrcode.QueryCanceled)
+}
+
+// IsConnectionLost checks if the error is caused by a lost or unavailable connection.
+// Client-side timeouts and cancellations are not connection errors.
+func IsConnectionLost(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return false
+	}
+
+	if pgErr, ok := AsPgError(err); ok {
+		return pgerrcode.IsConnectionException(pgErr.Code) ||
+			pgErr.Code == pgerrcode.AdminShutdown ||
+			pgErr.Code == pgerrcode.CrashShutdown ||
+			pgErr.Code == pgerrcode.CannotConnectNow
+	}
+
+	var (
+		connectErr *pgconn.ConnectError
+		opErr      *net.OpError
+	)
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &opErr) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// codeClass returns the class of the SQLSTATE code.
+func codeClass(code string) string {
+	const classLen = 2
+	if len(code) < classLen {
+		return ""
+	}
+	return code[:classLen]
+}
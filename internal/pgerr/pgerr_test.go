@@ -0,0 +1,80 @@
+package pgerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	//nolint:exhaustruct // test
+	pgErr := &pgconn.PgError{
+		Code:           pgerrcode.UniqueViolation,
+		Message:        "duplicate key value",
+		SchemaName:     "public",
+		TableName:      "users",
+		ConstraintName: "users_email_key",
+	}
+
+	err := fmt.Errorf("wrapped: %w", New("sql exec", "INSERT INTO users (email) VALUES ($1)", pgErr))
+
+	e, ok := AsError(err)
+	require.True(t, ok)
+	require.Equal(t, "sql exec", e.Op)
+	require.Equal(t, "INSERT INTO users (email) VALUES ($1)", e.SQL)
+	require.Equal(t, pgerrcode.UniqueViolation, e.Code)
+	require.Equal(t, "23", e.Class)
+	require.Equal(t, "public", e.Schema)
+	require.Equal(t, "users", e.Table)
+	require.Equal(t, "users_email_key", e.Constraint)
+	require.ErrorIs(t, err, pgErr)
+	require.Equal(t, "wrapped: sql exec: "+pgErr.Error()+" [INSERT INTO users (email) VALUES ($1)]", err.Error())
+
+	e = New("sql select", "SELECT 1", io.EOF)
+	require.Empty(t, e.Code)
+	require.Empty(t, e.Class)
+}
+
+func TestClassification(t *testing.T) {
+	t.Parallel()
+
+	pgErr := func(code string) error {
+		return New("sql exec", "", &pgconn.PgError{Code: code}) //nolint:exhaustruct // test
+	}
+
+	require.True(t, IsUniqueViolation(pgErr(pgerrcode.UniqueViolation)))
+	require.True(t, IsForeignKeyViolation(pgErr(pgerrcode.ForeignKeyViolation)))
+	require.True(t, IsNotNullViolation(pgErr(pgerrcode.NotNullViolation)))
+	require.True(t, IsCheckViolation(pgErr(pgerrcode.CheckViolation)))
+	require.True(t, IsConflict(pgErr(pgerrcode.UniqueViolation)))
+	require.True(t, IsConflict(pgErr(pgerrcode.ExclusionViolation)))
+	require.False(t, IsConflict(pgErr(pgerrcode.ForeignKeyViolation)))
+	require.True(t, IsRetryable(pgErr(pgerrcode.SerializationFailure)))
+	require.True(t, IsRetryable(pgErr(pgerrcode.DeadlockDetected)))
+	require.False(t, IsRetryable(pgErr(pgerrcode.UniqueViolation)))
+	require.True(t, IsLockTimeout(pgErr(pgerrcode.LockNotAvailable)))
+	require.True(t, IsQueryCanceled(pgErr(pgerrcode.QueryCanceled)))
+	require.True(t, IsConnectionLost(pgErr(pgerrcode.ConnectionFailure)))
+	require.True(t, IsConnectionLost(pgErr(pgerrcode.AdminShutdown)))
+	require.True(t, IsConnectionLost(fmt.Errorf("read: %w", io.ErrUnexpectedEOF)))
+	require.False(t, IsConnectionLost(pgErr(pgerrcode.QueryCanceled)))
+	require.False(t, IsConnectionLost(nil))
+	require.True(t, IsConnectionLost(&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET})) //nolint:exhaustruct // test
+	require.True(t, IsConnectionLost(fmt.Errorf("write: %w", net.ErrClosed)))
+	require.False(t, IsConnectionLost(context.DeadlineExceeded))
+	require.False(t, IsConnectionLost(fmt.Errorf("query: %w", context.Canceled)))
+	//nolint:exhaustruct // test
+	require.False(t, IsConnectionLost(&net.OpError{Op: "read", Net: "tcp", Err: context.DeadlineExceeded}))
+	require.False(t, IsUniqueViolation(errors.New("test")))
+	require.Equal(t, "40", Class(pgErr(pgerrcode.SerializationFailure)))
+}
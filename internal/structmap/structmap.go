@@ -0,0 +1,247 @@
+// Package structmap maps structs to table columns using `db` tags, the same tags scany uses.
+//
+// Tag format: `db:"column[,option...]"`. Options:
+//   - pk: the column is a part of the primary key;
+//   - readonly: the column is generated by the database and is never inserted or updated;
+//   - omitempty: the column is not inserted if the value is zero, so that the database default is used.
+//
+// Fields with `db:"-"` and unexported fields are ignored. Untagged fields are mapped to snake_case names,
+// embedded structs without a tag are flattened like in scany.
+package structmap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/georgysavva/scany/v2/dbscan"
+)
+
+var (
+	// ErrNotStruct type is not a struct.
+	ErrNotStruct = errors.New("not a struct")
+	// ErrDuplicateColumn several fields are mapped to the same column.
+	ErrDuplicateColumn = errors.New("duplicate column")
+	// ErrNilPointer value is a nil pointer to a struct.
+	ErrNilPointer = errors.New("nil pointer")
+)
+
+const (
+	optionPK        = "pk"
+	optionReadOnly  = "readonly"
+	optionOmitEmpty = "omitempty"
+)
+
+// Field struct field mapped to a column.
+type Field struct {
+	// Column column name.
+	Column string
+	// Index index sequence for reflect.Value.FieldByIndex.
+	Index []int
+	// PK the column is a part of the primary key.
+	PK bool
+	// ReadOnly the column is never inserted or updated.
+	ReadOnly bool
+	// OmitEmpty the column is not inserted if the value is zero.
+	OmitEmpty bool
+}
+
+// Struct columns of a struct type.
+type Struct struct {
+	// Fields in the order of declaration.
+	Fields []Field
+}
+
+var cache sync.Map //nolint:gochecknoglobals // cache of parsed types
+
+// Of returns columns of T. T must be a struct or a pointer to a struct.
+func Of[T any]() (*Struct, error) {
+	return Get(reflect.TypeFor[T]())
+}
+
+// Get returns columns of the type. The type must be a struct or a pointer to a struct.
+func Get(t reflect.Type) (*Struct, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if cached, ok := cache.Load(t); ok {
+		return cached.(*Struct), nil //nolint:forcetypeassert // only *Struct is stored
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: %w", t, ErrNotStruct)
+	}
+
+	s := &Struct{Fields: nil}
+	if err := s.parse(t, nil); err != nil {
+		return nil, fmt.Errorf("%s: %w", t, err)
+	}
+
+	cached, _ := cache.LoadOrStore(t, s)
+	return cached.(*Struct), nil //nolint:forcetypeassert // only *Struct is stored
+}
+
+func (s *Struct) parse(t reflect.Type, index []int) error {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if sf.Anonymous && !tagged {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := s.parse(ft, fieldIndex); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		field := Field{
+			Column:    strings.TrimSpace(parts[0]),
+			Index:     fieldIndex,
+			PK:        false,
+			ReadOnly:  false,
+			OmitEmpty: false,
+		}
+		if field.Column == "" {
+			field.Column = dbscan.SnakeCaseMapper(sf.Name)
+		}
+
+		for _, option := range parts[1:] {
+			switch strings.TrimSpace(option) {
+			case optionPK:
+				field.PK = true
+			case optionReadOnly:
+				field.ReadOnly = true
+			case optionOmitEmpty:
+				field.OmitEmpty = true
+			}
+		}
+
+		if s.Field(field.Column) != nil {
+			return fmt.Errorf("%s: %w", field.Column, ErrDuplicateColumn)
+		}
+
+		s.Fields = append(s.Fields, field)
+	}
+
+	return nil
+}
+
+// Field returns the field of the column or nil.
+func (s *Struct) Field(column string) *Field {
+	for i := range s.Fields {
+		if s.Fields[i].Column == column {
+			return &s.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+// Columns returns all columns.
+func (s *Struct) Columns() []string {
+	columns := make([]string, 0, len(s.Fields))
+	for _, f := range s.Fields {
+		columns = append(columns, f.Column)
+	}
+
+	return columns
+}
+
+// PK returns primary key fields.
+func (s *Struct) PK() []Field {
+	var pk []Field
+	for _, f := range s.Fields {
+		if f.PK {
+			pk = append(pk, f)
+		}
+	}
+
+	return pk
+}
+
+// Writable returns fields that can be inserted or updated.
+func (s *Struct) Writable() []Field {
+	var fields []Field
+	for _, f := range s.Fields {
+		if !f.ReadOnly {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// Value returns the value of the field. Returns nil if the field is inside a nil embedded pointer.
+func (f Field) Value(v reflect.Value) any {
+	fv, ok := fieldByIndex(reflect.Indirect(v), f.Index)
+	if !ok {
+		return nil
+	}
+
+	return fv.Interface()
+}
+
+// Indirect removes all pointer levels of v. Returns ErrNilPointer if any of them is nil.
+func Indirect(v reflect.Value) (reflect.Value, error) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return v, fmt.Errorf("%s: %w", v.Type(), ErrNilPointer)
+		}
+		v = v.Elem()
+	}
+
+	return v, nil
+}
+
+// Values returns values of the fields.
+func Values(v reflect.Value, fields []Field) []any {
+	values := make([]any, 0, len(fields))
+	for _, f := range fields {
+		values = append(values, f.Value(v))
+	}
+
+	return values
+}
+
+// ColumnsOf returns column names of the fields.
+func ColumnsOf(fields []Field) []string {
+	columns := make([]string, 0, len(fields))
+	for _, f := range fields {
+		columns = append(columns, f.Column)
+	}
+
+	return columns
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex that doesn't panic on nil embedded pointers.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
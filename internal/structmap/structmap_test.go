@@ -0,0 +1,75 @@
+package structmap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type base struct {
+	CreatedAt string `db:"created_at,readonly"`
+}
+
+type user struct {
+	*base
+	OrgID    int64  `db:"org_id,pk"`
+	ID       int64  `db:"id,pk"`
+	UserName string // untagged
+	Email    string `db:"email,omitempty"`
+	Ignored  string `db:"-"`
+	secret   string //nolint:unused // unexported fields are ignored
+}
+
+func TestOf(t *testing.T) {
+	t.Parallel()
+
+	s, err := Of[*user]()
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"created_at", "org_id", "id", "user_name", "email"}, s.Columns())
+	require.Equal(t, []string{"org_id", "id"}, ColumnsOf(s.PK()))
+	require.Equal(t, []string{"org_id", "id", "user_name", "email"}, ColumnsOf(s.Writable()))
+
+	// nil embedded pointer
+	u := user{base: nil, OrgID: 1, ID: 2, UserName: "bob", Email: "", Ignored: "", secret: ""}
+	v := reflect.ValueOf(&u)
+	require.Equal(t, []any{nil, int64(1), int64(2), "bob", ""}, Values(v, s.Fields))
+
+	u.Email = "bob@example.com"
+	u.base = &base{CreatedAt: "now"}
+	require.Equal(t, []any{"now", int64(1), int64(2), "bob", "bob@example.com"}, Values(v, s.Fields))
+
+	// cached
+	s2, err := Of[user]()
+	require.NoError(t, err)
+	require.Same(t, s, s2)
+}
+
+func TestOf_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Of[int]()
+	require.ErrorIs(t, err, ErrNotStruct)
+
+	type duplicate struct {
+		A int `db:"a"`
+		B int `db:"a"`
+	}
+	_, err = Of[duplicate]()
+	require.ErrorIs(t, err, ErrDuplicateColumn)
+}
+
+func TestIndirect(t *testing.T) {
+	t.Parallel()
+
+	u := &user{base: nil, OrgID: 1, ID: 2, UserName: "", Email: "", Ignored: "", secret: ""}
+	v, err := Indirect(reflect.ValueOf(&u))
+	require.NoError(t, err)
+	require.Equal(t, reflect.Struct, v.Kind())
+	require.Equal(t, int64(2), v.FieldByName("ID").Int())
+
+	var nilUser *user
+	_, err = Indirect(reflect.ValueOf(&nilUser))
+	require.ErrorIs(t, err, ErrNilPointer)
+}
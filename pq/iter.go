@@ -0,0 +1,59 @@
+package pq
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/georgysavva/scany/v2/sqlscan"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/internal/pgerr"
+	sq "github.com/n-r-w/squirrel"
+)
+
+// Iter - executes a query and returns an iterator over rows scanned into T. Querier can be either sql.Tx or sql.DB.
+// Unlike Select, rows are not loaded into memory. Rows are closed when the iteration is finished or stopped by break.
+// If an error occurs, it is returned with the zero value of T and the iteration stops.
+func Iter[T any](ctx context.Context, db IQuerier, sqlizer sq.Sqlizer) iter.Seq2[T, error] {
+	q, args, err := sqToSQL(ctx, sqlizer)
+	if err != nil {
+		return func(yield func(T, error) bool) {
+			var zero T
+			yield(zero, fmt.Errorf("pq.Iter to sql: %w", err))
+		}
+	}
+
+	return IterPlain[T](ctx, db, q, args)
+}
+
+// IterPlain - executes a query and returns an iterator over rows scanned into T. See Iter.
+// Querier can be either sql.Tx or sql.DB.
+func IterPlain[T any](ctx context.Context, db IQuerier, query string, args pgh.Args) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(zero, pgerr.New("sql select", query, err))
+			return
+		}
+		defer func() { _ = rows.Close() }()
+
+		scanner := sqlscan.NewRowScanner(rows)
+		for rows.Next() {
+			var v T
+			if err := scanner.Scan(&v); err != nil {
+				yield(zero, pgerr.New("sql scan", query, err))
+				return
+			}
+
+			if !yield(v, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(zero, pgerr.New("sql rows", query, err))
+		}
+	}
+}
@@ -10,13 +10,14 @@ import (
 
 	"github.com/georgysavva/scany/v2/sqlscan"
 	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/internal/pgerr"
 )
 
 // ExecPlain - executes a modification query. Querier can be either sql.Tx or sql.DB.
 func ExecPlain(ctx context.Context, db IQuerier, query string, args pgh.Args) (sql.Result, error) {
 	result, err := db.ExecContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("sql exec: %w [%s]", err, pgh.TruncSQL(query))
+		return nil, pgerr.New("sql exec", query, err)
 	}
 	return result, nil
 }
@@ -24,7 +25,7 @@ func ExecPlain(ctx context.Context, db IQuerier, query string, args pgh.Args) (s
 // SelectPlain - executes a query. Querier can be either sql.Tx or sql.DB.
 func SelectPlain[T any](ctx context.Context, db IQuerier, query string, dst *[]T, args pgh.Args) error {
 	if err := sqlscan.Select(ctx, db, dst, query, args...); err != nil {
-		return fmt.Errorf("sql select: %w [%s]", err, pgh.TruncSQL(query))
+		return pgerr.New("sql select", query, err)
 	}
 	return nil
 }
@@ -41,7 +42,7 @@ func SelectFuncPlain(
 	var rows *sql.Rows
 	rows, err = db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("sql select: %w [%s]", err, pgh.TruncSQL(query))
+		return pgerr.New("sql select", query, err)
 	}
 	defer func() {
 		err = errors.Join(err, rows.Close())
@@ -54,7 +55,7 @@ func SelectFuncPlain(
 	}
 
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("sql rows: %w [%s]", err, pgh.TruncSQL(query))
+		return pgerr.New("sql rows", query, err)
 	}
 
 	return nil
@@ -67,7 +68,7 @@ func SelectOnePlain[T any](ctx context.Context, db IQuerier, query string, dst *
 		if errors.Is(err, sql.ErrNoRows) {
 			return sql.ErrNoRows
 		}
-		return fmt.Errorf("sql select: %w [%s]", err, pgh.TruncSQL(query))
+		return pgerr.New("sql select", query, err)
 	}
 	return nil
 }
@@ -111,7 +112,7 @@ func InsertValuesPlain(ctx context.Context, db IQuerier, query string, values []
 	targetSQL := sqlBuilder.String()
 
 	if _, err := db.ExecContext(ctx, targetSQL, args...); err != nil {
-		return fmt.Errorf("pq.InsertValues: %w [%s]", err, pgh.TruncSQL(targetSQL))
+		return pgerr.New("pq.InsertValues", targetSQL, err)
 	}
 
 	return nil
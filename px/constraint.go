@@ -0,0 +1,180 @@
+package px
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2/internal/pgerr"
+)
+
+// ConstraintRegistry maps names of violated constraints to domain errors.
+// It is usually filled once at startup and used by all repositories:
+//
+//	var constraints = px.NewConstraintRegistry().
+//		Register("users_email_key", ErrEmailTaken).
+//		RegisterFunc("orders_user_id_fkey", func(pgErr *pgconn.PgError) error {
+//			return fmt.Errorf("%w: %s", ErrUserNotFound, pgErr.Detail)
+//		})
+//
+//	err := px.Exec(ctx, constraints.Querier(tx), query)
+//	if errors.Is(err, ErrEmailTaken) { ... }
+type ConstraintRegistry struct {
+	mu       sync.RWMutex
+	mappings map[string]func(pgErr *pgconn.PgError) error
+}
+
+// NewConstraintRegistry creates an empty ConstraintRegistry.
+func NewConstraintRegistry() *ConstraintRegistry {
+	return &ConstraintRegistry{ //nolint:exhaustruct // mutex zero value is ready to use
+		mappings: make(map[string]func(pgErr *pgconn.PgError) error),
+	}
+}
+
+// Register maps constraint to the domain error. The original error remains in the chain,
+// so both errors.Is(err, domainErr) and AsError work.
+func (r *ConstraintRegistry) Register(constraint string, domainErr error) *ConstraintRegistry {
+	return r.RegisterFunc(constraint, func(*pgconn.PgError) error {
+		return domainErr
+	})
+}
+
+// RegisterFunc maps constraint to a function that creates the domain error from pgconn.PgError,
+// e.g. using its Detail. The original error remains in the chain.
+func (r *ConstraintRegistry) RegisterFunc(constraint string, f func(pgErr *pgconn.PgError) error) *ConstraintRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mappings[constraint] = f
+
+	return r
+}
+
+// Translate returns the domain error for the constraint violated in err.
+// If err doesn't contain pgconn.PgError or its constraint is not registered, err is returned as is.
+func (r *ConstraintRegistry) Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	pgErr, ok := pgerr.AsPgError(err)
+	if !ok || pgErr.ConstraintName == "" {
+		return err
+	}
+
+	r.mu.RLock()
+	f, ok := r.mappings[pgErr.ConstraintName]
+	r.mu.RUnlock()
+
+	if !ok {
+		return err
+	}
+
+	domainErr := f(pgErr)
+	if domainErr == nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", domainErr, err)
+}
+
+// Querier returns IQuerier that translates errors of querier, including errors of returned rows.
+// It can be passed to any px helper that accepts IQuerier.
+func (r *ConstraintRegistry) Querier(querier IQuerier) IQuerier {
+	return &constraintQuerier{querier: querier, registry: r}
+}
+
+// Batcher returns IBatcher that translates errors of batch results.
+// It can be passed to any px helper that accepts IBatcher.
+func (r *ConstraintRegistry) Batcher(batcher IBatcher) IBatcher {
+	return &constraintBatcher{batcher: batcher, registry: r}
+}
+
+// constraintQuerier implements IQuerier with error translation.
+type constraintQuerier struct {
+	querier  IQuerier
+	registry *ConstraintRegistry
+}
+
+// Query executes a query and translates its errors.
+func (q *constraintQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := q.querier.Query(ctx, sql, args...)
+	if err != nil {
+		return rows, q.registry.Translate(err)
+	}
+
+	return &constraintRows{Rows: rows, registry: q.registry}, nil
+}
+
+// Exec executes a query and translates its errors.
+func (q *constraintQuerier) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	tag, err := q.querier.Exec(ctx, sql, arguments...)
+	return tag, q.registry.Translate(err)
+}
+
+// constraintRows translates errors of pgx.Rows. Violations of INSERT/UPDATE ... RETURNING are reported by rows.
+type constraintRows struct {
+	pgx.Rows
+	registry *ConstraintRegistry
+}
+
+// Err returns the translated error of rows.
+func (r *constraintRows) Err() error {
+	return r.registry.Translate(r.Rows.Err())
+}
+
+// constraintBatcher implements IBatcher with error translation.
+type constraintBatcher struct {
+	batcher  IBatcher
+	registry *ConstraintRegistry
+}
+
+// SendBatch sends a batch and translates errors of its results.
+func (b *constraintBatcher) SendBatch(ctx context.Context, batch *pgx.Batch) pgx.BatchResults {
+	return &constraintBatchResults{BatchResults: b.batcher.SendBatch(ctx, batch), registry: b.registry}
+}
+
+// constraintBatchResults translates errors of pgx.BatchResults.
+type constraintBatchResults struct {
+	pgx.BatchResults
+	registry *ConstraintRegistry
+}
+
+// Exec reads the results from the next query in the batch and translates its error.
+func (b *constraintBatchResults) Exec() (pgconn.CommandTag, error) {
+	tag, err := b.BatchResults.Exec()
+	return tag, b.registry.Translate(err)
+}
+
+// Query reads the results from the next query in the batch and translates its errors.
+func (b *constraintBatchResults) Query() (pgx.Rows, error) {
+	rows, err := b.BatchResults.Query()
+	if err != nil {
+		return rows, b.registry.Translate(err)
+	}
+
+	return &constraintRows{Rows: rows, registry: b.registry}, nil
+}
+
+// Close closes the batch operation and translates its error.
+func (b *constraintBatchResults) Close() error {
+	return b.registry.Translate(b.BatchResults.Close())
+}
+
+// QueryRow reads the results from the next query in the batch and translates the error of Scan.
+func (b *constraintBatchResults) QueryRow() pgx.Row {
+	return &constraintRow{row: b.BatchResults.QueryRow(), registry: b.registry}
+}
+
+// constraintRow translates the error of pgx.Row.
+type constraintRow struct {
+	row      pgx.Row
+	registry *ConstraintRegistry
+}
+
+// Scan reads the values and translates the error.
+func (r *constraintRow) Scan(dest ...any) error {
+	return r.registry.Translate(r.row.Scan(dest...))
+}
@@ -0,0 +1,105 @@
+package px
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	errEmailTaken   = errors.New("email taken")
+	errUserNotFound = errors.New("user not found")
+)
+
+func newConstraintRegistry() *ConstraintRegistry {
+	return NewConstraintRegistry().
+		Register("users_email_key", errEmailTaken).
+		RegisterFunc("orders_user_id_fkey", func(pgErr *pgconn.PgError) error {
+			return fmt.Errorf("%w: %s", errUserNotFound, pgErr.Detail)
+		})
+}
+
+func TestConstraintRegistry_Translate(t *testing.T) {
+	t.Parallel()
+
+	r := newConstraintRegistry()
+
+	//nolint:exhaustruct // test
+	err := r.Translate(&pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: "users_email_key"})
+	require.ErrorIs(t, err, errEmailTaken)
+	require.True(t, IsUniqueViolation(err))
+
+	//nolint:exhaustruct // test
+	err = r.Translate(&pgconn.PgError{
+		Code:           pgerrcode.ForeignKeyViolation,
+		ConstraintName: "orders_user_id_fkey",
+		Detail:         "Key (user_id)=(1) is not present",
+	})
+	require.ErrorIs(t, err, errUserNotFound)
+	require.ErrorContains(t, err, "Key (user_id)=(1) is not present")
+
+	// unknown constraint
+	//nolint:exhaustruct // test
+	pgErr := &pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: "other_key"}
+	require.Equal(t, error(pgErr), r.Translate(pgErr))
+
+	require.NoError(t, r.Translate(nil))
+	errTest := errors.New("test")
+	require.Equal(t, errTest, r.Translate(errTest))
+}
+
+func TestConstraintRegistry_Querier(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	querier := NewMockIQuerier(mc)
+	querier.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(pgconn.CommandTag{}, &pgconn.PgError{ //nolint:exhaustruct // test
+			Code:           pgerrcode.UniqueViolation,
+			ConstraintName: "users_email_key",
+		})
+
+	_, err := Exec(ctx, newConstraintRegistry().Querier(querier),
+		pgh.Builder().Insert("users").Columns("email").Values("a@b.c"))
+	require.ErrorIs(t, err, errEmailTaken)
+
+	e, ok := AsError(err)
+	require.True(t, ok)
+	require.Equal(t, "users_email_key", e.Constraint)
+}
+
+func TestConstraintRegistry_Batcher(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	batchMock := NewMockIBatcher(mc)
+	batchMock.EXPECT().SendBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *pgx.Batch) pgx.BatchResults {
+			batchResultMock := NewMockBatchResults(mc)
+			batchResultMock.EXPECT().Exec().Return(pgconn.CommandTag{}, &pgconn.PgError{ //nolint:exhaustruct // test
+				Code:           pgerrcode.ForeignKeyViolation,
+				ConstraintName: "orders_user_id_fkey",
+			})
+			batchResultMock.EXPECT().Close().Return(nil)
+
+			return batchResultMock
+		})
+
+	_, err := InsertSplit(ctx, newConstraintRegistry().Batcher(batchMock),
+		pgh.Builder().Insert("orders").Columns("user_id"), []pgh.Args{{1}}, 10)
+	require.ErrorIs(t, err, errUserNotFound)
+}
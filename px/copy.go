@@ -0,0 +1,266 @@
+package px
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/internal/structmap"
+	"github.com/n-r-w/pgh/v2/txmgr"
+)
+
+// Helpers for bulk loading structs with COPY. Columns are taken from `db` tags like in InsertStructs.
+// COPY can't insert DEFAULT, so the omitempty tag option is ignored and zero values are copied as is.
+
+// copyTempTablePrefix prefix of names of temporary tables used by CopyUpsertStructs and CopyUpdateStructs.
+const copyTempTablePrefix = "pgh_copy_"
+
+// copyTempTableSeq makes names of temporary tables unique, so a table left by a failed call
+// doesn't break the next calls within the same transaction.
+var copyTempTableSeq atomic.Uint64
+
+// CopyStructs inserts rows into table with COPY. Writable columns of T are copied, readonly are skipped.
+// It is much faster than InsertStructs for large amounts of data, but doesn't support ON CONFLICT.
+// table can be schema-qualified. copier can be either pgx.Tx, pg_types.Pool or conn.IConnection.
+// T can be a pointer to a struct, nil rows are not allowed. See CopyStructsSeq for streaming rows.
+func CopyStructs[T any](ctx context.Context, copier ICopier, table string, rows []T) (int64, error) {
+	return CopyStructsSeq(ctx, copier, table, slices.Values(rows))
+}
+
+// CopyStructsSeq is CopyStructs that reads rows from a sequence while copying, so rows don't have to be
+// in memory at once, e.g. for large imports from a file or another database.
+func CopyStructsSeq[T any](ctx context.Context, copier ICopier, table string, rows iter.Seq[T]) (int64, error) {
+	meta, err := structmap.Of[T]()
+	if err != nil {
+		return 0, fmt.Errorf("CopyStructs: %w", err)
+	}
+
+	fields := meta.Writable()
+
+	source := newStructSource(rows, fields)
+	defer source.stop()
+
+	n, err := copier.CopyFrom(ctx, copyIdentifier(table), structmap.ColumnsOf(fields), source)
+	if err != nil {
+		return 0, fmt.Errorf("CopyStructs: %w", err)
+	}
+
+	return n, nil
+}
+
+// CopyUpsertStructs copies rows into a temporary table and then inserts them into table with
+// INSERT ... SELECT ... ON CONFLICT built from opts (see UpsertBuilder). Returns the number of inserted or updated rows.
+// Rows must be unique by the conflict target for DO UPDATE.
+// Must be called within a transaction: the temporary table is dropped on commit. tx can be either pgx.Tx or
+// conn.IConnection with a started transaction, otherwise txmgr.ErrNoTransaction is returned.
+func CopyUpsertStructs[T any](
+	ctx context.Context,
+	tx ICopyQuerier,
+	table string,
+	rows []T,
+	opts ...UpsertOption,
+) (int64, error) {
+	meta, err := structmap.Of[T]()
+	if err != nil {
+		return 0, fmt.Errorf("CopyUpsertStructs: %w", err)
+	}
+
+	columns := structmap.ColumnsOf(meta.Writable())
+	tempTable := newCopyTempTable()
+
+	base := pgh.Builder().Insert(table).Columns(columns...).
+		Select(pgh.Builder().Select(columns...).From(tempTable))
+	query, err := UpsertBuilder(base, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("CopyUpsertStructs: %w", err)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("CopyUpsertStructs: %w", err)
+	}
+
+	n, err := copyThroughTempTable(ctx, tx, table, tempTable, meta.Writable(), rows, sql, args)
+	if err != nil {
+		return 0, fmt.Errorf("CopyUpsertStructs: %w", err)
+	}
+
+	return n, nil
+}
+
+// CopyUpdateStructs copies rows into a temporary table and then updates rows of table with
+// UPDATE ... FROM by the primary key. All writable columns except the primary key are updated.
+// Rows that don't exist in table are skipped. Returns the number of updated rows.
+// Must be called within a transaction: the temporary table is dropped on commit. tx can be either pgx.Tx or
+// conn.IConnection with a started transaction, otherwise txmgr.ErrNoTransaction is returned.
+func CopyUpdateStructs[T any](ctx context.Context, tx ICopyQuerier, table string, rows []T) (int64, error) {
+	meta, err := structmap.Of[T]()
+	if err != nil {
+		return 0, fmt.Errorf("CopyUpdateStructs: %w", err)
+	}
+
+	pk := meta.PK()
+	if len(pk) == 0 {
+		return 0, fmt.Errorf("CopyUpdateStructs: %s: %w", table, ErrNoPrimaryKey)
+	}
+
+	var (
+		set    []string
+		fields = pk
+	)
+	for _, f := range meta.Writable() {
+		if !f.PK {
+			set = append(set, fmt.Sprintf("%s = s.%s", f.Column, f.Column))
+			fields = append(fields, f)
+		}
+	}
+	if len(set) == 0 {
+		return 0, fmt.Errorf("CopyUpdateStructs: %s: %w", table, ErrNothingToUpdate)
+	}
+
+	where := make([]string, 0, len(pk))
+	for _, f := range pk {
+		where = append(where, fmt.Sprintf("t.%s = s.%s", f.Column, f.Column))
+	}
+
+	tempTable := newCopyTempTable()
+	sql := fmt.Sprintf("UPDATE %s AS t SET %s FROM %s AS s WHERE %s",
+		table, strings.Join(set, ", "), tempTable, strings.Join(where, " AND "))
+
+	n, err := copyThroughTempTable(ctx, tx, table, tempTable, fields, rows, sql, nil)
+	if err != nil {
+		return 0, fmt.Errorf("CopyUpdateStructs: %w", err)
+	}
+
+	return n, nil
+}
+
+// copyThroughTempTable creates the temporary table tempTable with columns of fields, copies rows into it,
+// executes sql and drops the table.
+func copyThroughTempTable[T any](
+	ctx context.Context,
+	tx ICopyQuerier,
+	table string,
+	tempTable string,
+	fields []structmap.Field,
+	rows []T,
+	sql string,
+	args []any,
+) (int64, error) {
+	// a temporary table is visible only to its connection, so a pool without a transaction can't be used
+	if !copyInTransaction(tx) {
+		return 0, txmgr.ErrNoTransaction
+	}
+
+	columns := structmap.ColumnsOf(fields)
+
+	// column types are taken from the target table, constraints and defaults are not copied
+	if _, err := ExecPlain(ctx, tx, fmt.Sprintf("CREATE TEMP TABLE %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA",
+		tempTable, strings.Join(columns, ", "), table), nil); err != nil {
+		return 0, err
+	}
+
+	source := newStructSource(slices.Values(rows), fields)
+	defer source.stop()
+
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{tempTable}, columns, source); err != nil {
+		return 0, err
+	}
+
+	tag, err := ExecPlain(ctx, tx, sql, args)
+	if err != nil {
+		return 0, err
+	}
+
+	// the table is dropped explicitly to allow several calls within one transaction
+	if _, err := ExecPlain(ctx, tx, "DROP TABLE "+tempTable, nil); err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// copyInTransaction returns true if tx is pgx.Tx or a connection with a started transaction,
+// e.g. conn.IConnection. Other queriers, such as a pool, are not bound to one connection.
+func copyInTransaction(tx ICopyQuerier) bool {
+	switch t := tx.(type) {
+	case pgx.Tx:
+		return true
+	case interface{ InTransaction() bool }:
+		return t.InTransaction()
+	default:
+		return false
+	}
+}
+
+// newCopyTempTable returns a unique name of a temporary table.
+func newCopyTempTable() string {
+	return copyTempTablePrefix + strconv.FormatUint(copyTempTableSeq.Add(1), 10)
+}
+
+// copyIdentifier converts a possibly schema-qualified table name to pgx.Identifier.
+func copyIdentifier(table string) pgx.Identifier {
+	return pgx.Identifier(strings.Split(table, "."))
+}
+
+// structSource implements pgx.CopyFromSource for a sequence of structs.
+// Rows are pulled from the sequence and values are read from structs on demand without copying all rows.
+type structSource[T any] struct {
+	next   func() (T, bool)
+	stop   func()
+	fields []structmap.Field
+	row    T
+	idx    int
+}
+
+// newStructSource creates structSource. stop must be called to release the sequence.
+func newStructSource[T any](rows iter.Seq[T], fields []structmap.Field) *structSource[T] {
+	next, stop := iter.Pull(rows)
+
+	return &structSource[T]{ //nolint:exhaustruct // row is set by Next
+		next:   next,
+		stop:   stop,
+		fields: fields,
+		idx:    -1,
+	}
+}
+
+// Next returns true if there is another row and makes the next row current.
+func (s *structSource[T]) Next() bool {
+	row, ok := s.next()
+	if !ok {
+		return false
+	}
+
+	s.row = row
+	s.idx++
+
+	return true
+}
+
+// Values returns the values for the current row.
+func (s *structSource[T]) Values() ([]any, error) {
+	v, err := structmap.Indirect(reflect.ValueOf(s.row))
+	if err != nil {
+		return nil, fmt.Errorf("row %d: %w", s.idx, err)
+	}
+
+	values := make([]any, len(s.fields))
+	for i, f := range s.fields {
+		values[i] = f.Value(v)
+	}
+
+	return values, nil
+}
+
+// Err returns any error that has been encountered by the source.
+func (s *structSource[T]) Err() error {
+	return nil
+}
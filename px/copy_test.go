@@ -0,0 +1,218 @@
+package px
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2/internal/structmap"
+	"github.com/n-r-w/pgh/v2/txmgr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// copyRows reads all rows from the source.
+func copyRows(t *testing.T, src pgx.CopyFromSource) [][]any {
+	t.Helper()
+
+	var rows [][]any
+	for src.Next() {
+		values, err := src.Values()
+		require.NoError(t, err)
+		rows = append(rows, values)
+	}
+	require.NoError(t, src.Err())
+
+	return rows
+}
+
+func TestCopyStructs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	copier := NewMockICopier(mc)
+	copier.EXPECT().CopyFrom(gomock.Any(), pgx.Identifier{"public", "items"}, []string{"name", "status", "comment"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ pgx.Identifier, _ []string, src pgx.CopyFromSource) (int64, error) {
+			require.Equal(t, [][]any{{"a", "new", ""}, {"b", "", "c"}}, copyRows(t, src))
+			return 2, nil
+		})
+
+	n, err := CopyStructs(ctx, copier, "public.items", []structItem{
+		{ID: 1, Name: "a", Status: "new", Comment: "", Ignored: "x"},
+		{ID: 2, Name: "b", Status: "", Comment: "c", Ignored: "y"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+}
+
+func TestCopyStructsSeq(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	// rows are generated while copying
+	generated := 0
+	rows := func(yield func(*structItem) bool) {
+		for i := range 3 {
+			generated++
+			if !yield(&structItem{ID: 0, Name: strconv.Itoa(i), Status: "", Comment: "", Ignored: ""}) {
+				return
+			}
+		}
+	}
+
+	copier := NewMockICopier(mc)
+	copier.EXPECT().CopyFrom(gomock.Any(), pgx.Identifier{"items"}, []string{"name", "status", "comment"}, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ pgx.Identifier, _ []string, src pgx.CopyFromSource) (int64, error) {
+			require.Equal(t, 0, generated)
+			require.True(t, src.Next())
+			require.Equal(t, 1, generated)
+			values, err := src.Values()
+			require.NoError(t, err)
+			require.Equal(t, []any{"0", "", ""}, values)
+
+			require.Equal(t, [][]any{{"1", "", ""}, {"2", "", ""}}, copyRows(t, src))
+			return 3, nil
+		})
+
+	n, err := CopyStructsSeq(ctx, copier, "items", rows)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+
+	// nil rows are not allowed
+	copier.EXPECT().CopyFrom(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ pgx.Identifier, _ []string, src pgx.CopyFromSource) (int64, error) {
+			require.True(t, src.Next())
+			_, err := src.Values()
+			return 0, err
+		})
+
+	_, err = CopyStructs(ctx, copier, "items", []*structItem{nil})
+	require.ErrorIs(t, err, structmap.ErrNilPointer)
+}
+
+func TestCopyUpsertStructs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	tx := NewMockICopyQuerier(mc)
+	gomock.InOrder(
+		tx.EXPECT().Exec(gomock.Any(), copySQL(
+			"CREATE TEMP TABLE pgh_copy ON COMMIT DROP AS SELECT name, status, comment FROM items WITH NO DATA")).
+			Return(pgconn.NewCommandTag("SELECT 0"), nil),
+		tx.EXPECT().CopyFrom(gomock.Any(), copyTable(), []string{"name", "status", "comment"}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ pgx.Identifier, _ []string, src pgx.CopyFromSource) (int64, error) {
+				require.Equal(t, [][]any{{"a", "new", ""}}, copyRows(t, src))
+				return 1, nil
+			}),
+		tx.EXPECT().Exec(gomock.Any(), copySQL(
+			"INSERT INTO items (name,status,comment) SELECT name, status, comment FROM pgh_copy "+
+				"ON CONFLICT (name) DO UPDATE SET status = EXCLUDED.status")).
+			Return(pgconn.NewCommandTag("INSERT 0 1"), nil),
+		tx.EXPECT().Exec(gomock.Any(), copySQL("DROP TABLE pgh_copy")).
+			Return(pgconn.NewCommandTag("DROP TABLE"), nil),
+	)
+
+	n, err := CopyUpsertStructs(ctx, txCopier{tx}, "items",
+		[]structItem{{ID: 0, Name: "a", Status: "new", Comment: "", Ignored: ""}},
+		OnConflictColumns("name"), DoUpdate("status"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+}
+
+func TestCopyUpdateStructs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	tx := NewMockICopyQuerier(mc)
+	gomock.InOrder(
+		tx.EXPECT().Exec(gomock.Any(), copySQL(
+			"CREATE TEMP TABLE pgh_copy ON COMMIT DROP AS SELECT id, name, status, comment FROM items WITH NO DATA")).
+			Return(pgconn.NewCommandTag("SELECT 0"), nil),
+		tx.EXPECT().CopyFrom(gomock.Any(), copyTable(), []string{"id", "name", "status", "comment"}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ pgx.Identifier, _ []string, src pgx.CopyFromSource) (int64, error) {
+				require.Equal(t, [][]any{{int64(1), "a", "new", ""}}, copyRows(t, src))
+				return 1, nil
+			}),
+		tx.EXPECT().Exec(gomock.Any(), copySQL(
+			"UPDATE items AS t SET name = s.name, status = s.status, comment = s.comment FROM pgh_copy AS s WHERE t.id = s.id")).
+			Return(pgconn.NewCommandTag("UPDATE 1"), nil),
+		tx.EXPECT().Exec(gomock.Any(), copySQL("DROP TABLE pgh_copy")).
+			Return(pgconn.NewCommandTag("DROP TABLE"), nil),
+	)
+
+	n, err := CopyUpdateStructs(ctx, txCopier{tx}, "items",
+		[]structItem{{ID: 1, Name: "a", Status: "new", Comment: "", Ignored: ""}})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+}
+
+// copyTempTableRe matches names of temporary tables of copyThroughTempTable.
+var copyTempTableRe = regexp.MustCompile(copyTempTablePrefix + `\d+`)
+
+// copySQL matches sql with any temporary table name in place of pgh_copy.
+func copySQL(sql string) gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		return copyTempTableRe.ReplaceAllString(x.(string), "pgh_copy") == sql
+	})
+}
+
+// copyTable matches pgx.Identifier of a temporary table.
+func copyTable() gomock.Matcher {
+	return gomock.Cond(func(x any) bool {
+		id := x.(pgx.Identifier)
+		return len(id) == 1 && copyTempTableRe.MatchString(id[0])
+	})
+}
+
+// txCopier imitates conn.IConnection with a started transaction.
+type txCopier struct {
+	*MockICopyQuerier
+}
+
+func (txCopier) InTransaction() bool { return true }
+
+// noTxCopier imitates conn.IConnection without transaction.
+type noTxCopier struct {
+	*MockICopyQuerier
+}
+
+func (noTxCopier) InTransaction() bool { return false }
+
+func TestCopyUpsertStructsNoTransaction(t *testing.T) {
+	t.Parallel()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	rows := []structItem{{ID: 0, Name: "a", Status: "", Comment: "", Ignored: ""}}
+
+	_, err := CopyUpsertStructs(context.Background(), noTxCopier{NewMockICopyQuerier(mc)}, "items", rows)
+	require.ErrorIs(t, err, txmgr.ErrNoTransaction)
+
+	// a querier that is not bound to a transaction, e.g. a pool
+	_, err = CopyUpsertStructs(context.Background(), NewMockICopyQuerier(mc), "items", rows)
+	require.ErrorIs(t, err, txmgr.ErrNoTransaction)
+}
+
+func TestNewCopyTempTable(t *testing.T) {
+	t.Parallel()
+
+	first, second := newCopyTempTable(), newCopyTempTable()
+	require.Regexp(t, copyTempTableRe, first)
+	require.NotEqual(t, first, second)
+}
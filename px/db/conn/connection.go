@@ -3,6 +3,7 @@ package conn
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -51,7 +52,37 @@ func WithLogQueries() ConnectionOption {
 	}
 }
 
+// WithPrimary forces the primary database for all queries of the connection, e.g. to read your own writes.
+// Makes sense only if read replicas are configured.
+func WithPrimary() ConnectionOption {
+	return func(o *ConnectionOptionData) {
+		o.Primary = true
+	}
+}
+
+// WithStatementTimeout limits the execution time of each query of the connection.
+// The query context is canceled after the timeout, statement_timeout is also set on the server for each query.
+// Within a transaction, it is set before each query of the connection and the previous value is restored after it,
+// so other queries of the transaction are not affected. Without a transaction, each query is executed
+// in its own transaction, so queries that can't run in a transaction block can't be used with it.
+func WithStatementTimeout(timeout time.Duration) ConnectionOption {
+	return func(o *ConnectionOptionData) {
+		o.StatementTimeout = timeout
+	}
+}
+
+// WithLockTimeout limits the time each query of the connection waits for locks on the server.
+// lock_timeout is set in the same way as statement_timeout of WithStatementTimeout.
+func WithLockTimeout(timeout time.Duration) ConnectionOption {
+	return func(o *ConnectionOptionData) {
+		o.LockTimeout = timeout
+	}
+}
+
 // ConnectionOptionData option data for Connection.
 type ConnectionOptionData struct {
-	LogQueries bool
+	LogQueries       bool
+	Primary          bool
+	StatementTimeout time.Duration
+	LockTimeout      time.Duration
 }
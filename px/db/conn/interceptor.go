@@ -0,0 +1,419 @@
+package conn
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2/txmgr"
+)
+
+// Commands of IConnection passed to interceptors in QueryInfo.Command.
+const (
+	CommandExec      = "exec"
+	CommandQuery     = "query"
+	CommandQueryRow  = "query row"
+	CommandSendBatch = "send batch"
+	CommandCopyFrom  = "copy from"
+	// CommandBegin, CommandCommit and CommandRollback are passed to interceptors of PxDB
+	// for the transaction lifecycle. SQL is "BEGIN", "COMMIT" or "ROLLBACK" and can't be changed.
+	CommandBegin    = "begin"
+	CommandCommit   = "commit"
+	CommandRollback = "rollback"
+)
+
+// QueryInfo information about a query passed to interceptors.
+type QueryInfo struct {
+	// Command one of Command* constants.
+	Command string
+	// SQL query text. Before can change it. Empty for CommandSendBatch and CommandCopyFrom.
+	SQL string
+	// Args query arguments. Before can change them.
+	Args []any
+	// Batch queries of CommandSendBatch. Before can change them.
+	Batch *pgx.Batch
+	// Table table of CommandCopyFrom.
+	Table pgx.Identifier
+	// Columns columns of CommandCopyFrom.
+	Columns []string
+	// InTransaction is true if the query is executed within a transaction.
+	InTransaction bool
+	// TransactionOptions options of the transaction.
+	TransactionOptions txmgr.Options
+
+	// Start time when the query was started. Set before Before is called.
+	Start time.Time
+	// Duration query duration. Set before After is called. For queries returning rows it includes reading them.
+	Duration time.Duration
+	// RowsAffected number of rows affected or returned by the query, for SendBatch the total of all its queries.
+	// Set before After is called.
+	RowsAffected int64
+	// Err query error. Set before After is called.
+	// pgx.ErrNoRows of QueryRow is not an error of the query, it is passed as zero RowsAffected.
+	Err error
+}
+
+// Interceptor intercepts queries of IConnection. It can be used for logging, metrics, tracing, auditing,
+// rewriting or blocking queries without implementing the whole IConnection.
+type Interceptor interface {
+	// Before is called before the query. The returned context is passed to the query and to After.
+	// If an error is returned, the query is not executed and the error is returned to the caller.
+	Before(ctx context.Context, q *QueryInfo) (context.Context, error)
+	// After is called after the query is finished. For queries returning rows it is called when rows are closed,
+	// for QueryRow after Scan and for SendBatch when the batch results are closed.
+	After(ctx context.Context, q *QueryInfo)
+}
+
+// InterceptorFuncs implements Interceptor with functions. Nil functions are skipped.
+type InterceptorFuncs struct {
+	BeforeFunc func(ctx context.Context, q *QueryInfo) (context.Context, error)
+	AfterFunc  func(ctx context.Context, q *QueryInfo)
+}
+
+// Before calls BeforeFunc.
+func (f InterceptorFuncs) Before(ctx context.Context, q *QueryInfo) (context.Context, error) {
+	if f.BeforeFunc == nil {
+		return ctx, nil
+	}
+
+	return f.BeforeFunc(ctx, q)
+}
+
+// After calls AfterFunc.
+func (f InterceptorFuncs) After(ctx context.Context, q *QueryInfo) {
+	if f.AfterFunc != nil {
+		f.AfterFunc(ctx, q)
+	}
+}
+
+// Intercept returns IConnection that calls interceptors for each query of con.
+// Before hooks are called in the order of interceptors, After hooks in the reverse order,
+// so the first interceptor wraps all others. If there are no interceptors, con is returned as is.
+func Intercept(con IConnection, interceptors ...Interceptor) IConnection {
+	if len(interceptors) == 0 {
+		return con
+	}
+
+	return &interceptedConnection{
+		IConnection:  con,
+		interceptors: interceptors,
+	}
+}
+
+// interceptedConnection calls interceptors for queries of IConnection.
+// Methods that don't execute queries are passed through.
+type interceptedConnection struct {
+	IConnection
+	interceptors []Interceptor
+}
+
+// Exec executes a query without returning data.
+func (c *interceptedConnection) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	q := c.newQueryInfo(CommandExec, sql, arguments)
+
+	ctx, finish, err := c.before(ctx, q)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	tag, err := c.IConnection.Exec(ctx, q.SQL, q.Args...)
+	finish(tag.RowsAffected(), err)
+
+	return tag, err
+}
+
+// Query executes a query and returns the result. After is called when rows are closed.
+func (c *interceptedConnection) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q := c.newQueryInfo(CommandQuery, sql, args)
+
+	ctx, finish, err := c.before(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := c.IConnection.Query(ctx, q.SQL, q.Args...) //nolint:sqlclosecheck // will be closed by caller
+	if err != nil {
+		finish(0, err)
+		return rows, err
+	}
+
+	return &interceptedRows{Rows: rows, finish: finish, once: sync.Once{}}, nil
+}
+
+// QueryRow executes a query that returns at most one row. After is called after Scan.
+func (c *interceptedConnection) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q := c.newQueryInfo(CommandQueryRow, sql, args)
+
+	ctx, finish, err := c.before(ctx, q)
+	if err != nil {
+		return NewErrRow(err)
+	}
+
+	return &interceptedRow{row: c.IConnection.QueryRow(ctx, q.SQL, q.Args...), finish: finish}
+}
+
+// SendBatch sends a batch of queries. After is called when the results are closed.
+func (c *interceptedConnection) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	q := c.newQueryInfo(CommandSendBatch, "", nil)
+	q.Batch = b
+
+	ctx, finish, err := c.before(ctx, q)
+	if err != nil {
+		return newErrBatchResults(err)
+	}
+
+	return &interceptedBatchResults{
+		BatchResults: c.IConnection.SendBatch(ctx, q.Batch),
+		finish:       finish,
+		rowsAffected: 0,
+		err:          nil,
+		rows:         nil,
+	}
+}
+
+// CopyFrom implements bulk data insertion into a table.
+func (c *interceptedConnection) CopyFrom(ctx context.Context, tableName pgx.Identifier,
+	columnNames []string, rowSrc pgx.CopyFromSource,
+) (int64, error) {
+	q := c.newQueryInfo(CommandCopyFrom, "", nil)
+	q.Table = tableName
+	q.Columns = columnNames
+
+	ctx, finish, err := c.before(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := c.IConnection.CopyFrom(ctx, q.Table, q.Columns, rowSrc)
+	finish(n, err)
+
+	return n, err
+}
+
+func (c *interceptedConnection) newQueryInfo(command, sql string, args []any) *QueryInfo {
+	inTransaction := c.IConnection.InTransaction()
+
+	//nolint:exhaustruct // results are set after the query
+	q := &QueryInfo{
+		Command:       command,
+		SQL:           sql,
+		Args:          args,
+		InTransaction: inTransaction,
+		Start:         time.Now(),
+	}
+	if inTransaction {
+		q.TransactionOptions = c.IConnection.TransactionOptions()
+	}
+
+	return q
+}
+
+// before calls Before hooks of the connection interceptors.
+func (c *interceptedConnection) before(ctx context.Context, q *QueryInfo,
+) (context.Context, func(rowsAffected int64, err error), error) {
+	return before(ctx, c.interceptors, q)
+}
+
+// Run calls interceptors around f for operations that are not methods of IConnection,
+// e.g. the transaction lifecycle. If a Before hook returns an error, f is not called.
+// f returns the number of affected rows and the error passed to After hooks.
+func Run(ctx context.Context, interceptors []Interceptor, q *QueryInfo,
+	f func(ctx context.Context) (int64, error),
+) error {
+	if q.Start.IsZero() {
+		q.Start = time.Now()
+	}
+
+	ctx, finish, err := before(ctx, interceptors, q)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := f(ctx)
+	finish(rowsAffected, err)
+
+	return err
+}
+
+// before calls Before hooks and returns the context for the query and the function that calls After hooks.
+// If a hook returns an error, After hooks of the preceding interceptors are called immediately.
+func before(ctx context.Context, interceptors []Interceptor, q *QueryInfo,
+) (context.Context, func(rowsAffected int64, err error), error) {
+	contexts := make([]context.Context, 0, len(interceptors))
+
+	after := func(rowsAffected int64, err error) {
+		q.Duration = time.Since(q.Start)
+		q.RowsAffected = rowsAffected
+		q.Err = err
+
+		for i := len(contexts) - 1; i >= 0; i-- {
+			interceptors[i].After(contexts[i], q)
+		}
+	}
+
+	for _, interceptor := range interceptors {
+		ctxNext, err := interceptor.Before(ctx, q)
+		if err != nil {
+			after(0, err)
+			return ctx, nil, err
+		}
+
+		ctx = ctxNext
+		contexts = append(contexts, ctx)
+	}
+
+	return ctx, after, nil
+}
+
+// interceptedRows calls After hooks when rows are closed.
+type interceptedRows struct {
+	pgx.Rows
+	finish func(rowsAffected int64, err error)
+	once   sync.Once
+}
+
+// Close closes rows and calls After hooks.
+func (r *interceptedRows) Close() {
+	r.Rows.Close()
+	r.once.Do(func() {
+		r.finish(r.Rows.CommandTag().RowsAffected(), r.Rows.Err())
+	})
+}
+
+// interceptedRow calls After hooks after Scan.
+type interceptedRow struct {
+	row    pgx.Row
+	finish func(rowsAffected int64, err error)
+}
+
+// Scan reads the values and calls After hooks.
+func (r *interceptedRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	r.finish(scanResult(err))
+
+	return err
+}
+
+// scanResult returns the result of the query of pgx.Row for After hooks by the error of Scan.
+// pgx.ErrNoRows means that the query succeeded without rows.
+func scanResult(err error) (rowsAffected int64, errQuery error) {
+	switch {
+	case err == nil:
+		return 1, nil
+	case errors.Is(err, pgx.ErrNoRows):
+		return 0, nil
+	default:
+		return 0, err
+	}
+}
+
+// interceptedBatchResults calls After hooks when batch results are closed.
+// Rows affected and returned by all queries of the batch and the first error are passed to After hooks.
+type interceptedBatchResults struct {
+	pgx.BatchResults
+	finish       func(rowsAffected int64, err error)
+	rowsAffected int64
+	err          error
+	// rows of the previous Query that may be not closed yet
+	rows *interceptedBatchRows
+}
+
+// Exec reads the results from the next query in the batch.
+func (r *interceptedBatchResults) Exec() (pgconn.CommandTag, error) {
+	r.flushRows()
+
+	tag, err := r.BatchResults.Exec()
+	r.add(tag.RowsAffected(), err)
+
+	return tag, err
+}
+
+// Query reads the results from the next query in the batch.
+func (r *interceptedBatchResults) Query() (pgx.Rows, error) {
+	r.flushRows()
+
+	rows, err := r.BatchResults.Query()
+	if err != nil {
+		r.add(0, err)
+		return rows, err
+	}
+
+	r.rows = &interceptedBatchRows{Rows: rows, results: r, once: sync.Once{}}
+
+	return r.rows, nil
+}
+
+// QueryRow reads the results from the next query in the batch.
+func (r *interceptedBatchResults) QueryRow() pgx.Row {
+	r.flushRows()
+
+	return &interceptedBatchRow{row: r.BatchResults.QueryRow(), results: r}
+}
+
+// Close closes the batch results and calls After hooks.
+func (r *interceptedBatchResults) Close() error {
+	r.flushRows()
+
+	err := r.BatchResults.Close()
+	if r.finish != nil {
+		r.add(0, err)
+		r.finish(r.rowsAffected, r.err)
+		r.finish = nil
+	}
+
+	return err
+}
+
+// add adds the result of a query to the batch totals.
+func (r *interceptedBatchResults) add(rowsAffected int64, err error) {
+	r.rowsAffected += rowsAffected
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// flushRows adds the result of the previous Query, pgx closes its rows when the next result is read.
+func (r *interceptedBatchResults) flushRows() {
+	if r.rows != nil {
+		r.rows.done()
+		r.rows = nil
+	}
+}
+
+// interceptedBatchRows adds the result of a batch query to the batch totals when rows are closed.
+type interceptedBatchRows struct {
+	pgx.Rows
+	results *interceptedBatchResults
+	once    sync.Once
+}
+
+// Close closes rows and adds their result to the batch totals.
+func (r *interceptedBatchRows) Close() {
+	r.Rows.Close()
+	r.done()
+}
+
+// done adds the result of the closed rows to the batch totals once.
+func (r *interceptedBatchRows) done() {
+	r.once.Do(func() {
+		r.Rows.Close()
+		r.results.add(r.Rows.CommandTag().RowsAffected(), r.Rows.Err())
+	})
+}
+
+// interceptedBatchRow adds the result of a batch query to the batch totals after Scan.
+type interceptedBatchRow struct {
+	row     pgx.Row
+	results *interceptedBatchResults
+}
+
+// Scan reads the values and adds the result to the batch totals.
+func (r *interceptedBatchRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	r.results.add(scanResult(err))
+
+	return err
+}
@@ -0,0 +1,312 @@
+package conn
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/n-r-w/pgh/v2/txmgr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type interceptorCtxKey struct{}
+
+// recordInterceptor records calls of hooks to calls.
+func recordInterceptor(t *testing.T, name string, calls *[]string) Interceptor {
+	t.Helper()
+
+	return InterceptorFuncs{
+		BeforeFunc: func(ctx context.Context, _ *QueryInfo) (context.Context, error) {
+			*calls = append(*calls, "before "+name)
+			return context.WithValue(ctx, interceptorCtxKey{}, name), nil
+		},
+		AfterFunc: func(ctx context.Context, _ *QueryInfo) {
+			require.Equal(t, name, ctx.Value(interceptorCtxKey{}))
+			*calls = append(*calls, "after "+name)
+		},
+	}
+}
+
+func TestIntercept_Exec(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	con := NewMockIConnection(ctrl)
+
+	con.EXPECT().InTransaction().Return(false)
+	con.EXPECT().Exec(gomock.Any(), "UPDATE users SET name = $1 WHERE tenant = $2", "alice", 42).
+		DoAndReturn(func(ctx context.Context, _ string, _ ...any) (pgconn.CommandTag, error) {
+			require.Equal(t, "second", ctx.Value(interceptorCtxKey{}))
+			return pgconn.NewCommandTag("UPDATE 3"), nil
+		})
+
+	var (
+		calls []string
+		info  *QueryInfo
+	)
+	rewrite := InterceptorFuncs{
+		BeforeFunc: func(ctx context.Context, q *QueryInfo) (context.Context, error) {
+			q.SQL += " WHERE tenant = $2"
+			q.Args = append(q.Args, 42)
+			return ctx, nil
+		},
+		AfterFunc: func(_ context.Context, q *QueryInfo) {
+			info = q
+		},
+	}
+
+	ic := Intercept(con, recordInterceptor(t, "first", &calls), recordInterceptor(t, "second", &calls), rewrite)
+
+	tag, err := ic.Exec(context.Background(), "UPDATE users SET name = $1", "alice")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), tag.RowsAffected())
+	require.Equal(t, []string{"before first", "before second", "after second", "after first"}, calls)
+
+	require.Equal(t, CommandExec, info.Command)
+	require.False(t, info.InTransaction)
+	require.Equal(t, int64(3), info.RowsAffected)
+	require.NoError(t, info.Err)
+	require.Positive(t, info.Duration)
+}
+
+func TestIntercept_Block(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	con := NewMockIConnection(ctrl)
+	con.EXPECT().InTransaction().Return(false).Times(2)
+
+	errBlocked := errors.New("blocked")
+
+	var (
+		calls    []string
+		afterErr error
+	)
+	first := InterceptorFuncs{
+		BeforeFunc: func(ctx context.Context, _ *QueryInfo) (context.Context, error) {
+			return ctx, nil
+		},
+		AfterFunc: func(_ context.Context, q *QueryInfo) {
+			afterErr = q.Err
+		},
+	}
+	block := InterceptorFuncs{
+		BeforeFunc: func(ctx context.Context, _ *QueryInfo) (context.Context, error) {
+			return ctx, errBlocked
+		},
+		AfterFunc: nil,
+	}
+
+	ic := Intercept(con, first, block, recordInterceptor(t, "never", &calls))
+
+	_, err := ic.Exec(context.Background(), "DELETE FROM users")
+	require.ErrorIs(t, err, errBlocked)
+	require.ErrorIs(t, afterErr, errBlocked)
+	require.Empty(t, calls)
+
+	require.ErrorIs(t, ic.QueryRow(context.Background(), "SELECT 1").Scan(), errBlocked)
+}
+
+func TestIntercept_Query(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	con := NewMockIConnection(ctrl)
+	rows := px.NewMockRows(ctrl)
+
+	con.EXPECT().InTransaction().Return(true)
+	//nolint:exhaustruct // test
+	con.EXPECT().TransactionOptions().Return(txmgr.Options{Mode: txmgr.TxReadOnly})
+	con.EXPECT().Query(gomock.Any(), "SELECT id FROM users").Return(rows, nil)
+	rows.EXPECT().Close().Times(2)
+	rows.EXPECT().CommandTag().Return(pgconn.NewCommandTag("SELECT 2"))
+	rows.EXPECT().Err().Return(nil)
+
+	var infos []*QueryInfo
+	ic := Intercept(con, InterceptorFuncs{
+		BeforeFunc: nil,
+		AfterFunc: func(_ context.Context, q *QueryInfo) {
+			infos = append(infos, q)
+		},
+	})
+
+	res, err := ic.Query(context.Background(), "SELECT id FROM users")
+	require.NoError(t, err)
+	require.Empty(t, infos, "after must be called when rows are closed")
+
+	res.Close()
+	res.Close()
+	require.Len(t, infos, 1)
+	require.Equal(t, CommandQuery, infos[0].Command)
+	require.True(t, infos[0].InTransaction)
+	require.Equal(t, txmgr.TxReadOnly, infos[0].TransactionOptions.Mode)
+	require.Equal(t, int64(2), infos[0].RowsAffected)
+}
+
+func TestIntercept_QueryRowNoRows(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	con := NewMockIConnection(ctrl)
+	row := px.NewMockRow(ctrl)
+
+	con.EXPECT().InTransaction().Return(false)
+	con.EXPECT().QueryRow(gomock.Any(), "SELECT name FROM users WHERE id = $1", 1).Return(row)
+	row.EXPECT().Scan(gomock.Any()).Return(pgx.ErrNoRows)
+
+	var info *QueryInfo
+	ic := Intercept(con, InterceptorFuncs{
+		BeforeFunc: nil,
+		AfterFunc: func(_ context.Context, q *QueryInfo) {
+			info = q
+		},
+	})
+
+	// the caller gets pgx.ErrNoRows, After hooks get a successful query without rows
+	var name string
+	require.ErrorIs(t, ic.QueryRow(context.Background(), "SELECT name FROM users WHERE id = $1", 1).Scan(&name),
+		pgx.ErrNoRows)
+	require.Equal(t, CommandQueryRow, info.Command)
+	require.Zero(t, info.RowsAffected)
+	require.NoError(t, info.Err)
+}
+
+func TestIntercept_SendBatch(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	con := NewMockIConnection(ctrl)
+	res := px.NewMockBatchResults(ctrl)
+
+	errExec := errors.New("exec failed")
+	batch := &pgx.Batch{} //nolint:exhaustruct // test
+	batch.Queue("DELETE FROM users")
+
+	con.EXPECT().InTransaction().Return(false)
+	con.EXPECT().SendBatch(gomock.Any(), batch).Return(res)
+	res.EXPECT().Exec().Return(pgconn.NewCommandTag("DELETE 5"), nil)
+	res.EXPECT().Exec().Return(pgconn.CommandTag{}, errExec)
+	res.EXPECT().Close().Return(nil)
+
+	var info *QueryInfo
+	ic := Intercept(con, InterceptorFuncs{
+		BeforeFunc: nil,
+		AfterFunc: func(_ context.Context, q *QueryInfo) {
+			info = q
+		},
+	})
+
+	br := ic.SendBatch(context.Background(), batch)
+	_, err := br.Exec()
+	require.NoError(t, err)
+	_, err = br.Exec()
+	require.ErrorIs(t, err, errExec)
+	require.Nil(t, info)
+
+	require.NoError(t, br.Close())
+	require.Equal(t, CommandSendBatch, info.Command)
+	require.Same(t, batch, info.Batch)
+	require.Equal(t, int64(5), info.RowsAffected)
+	require.ErrorIs(t, info.Err, errExec)
+}
+
+func TestIntercept_SendBatchQuery(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	con := NewMockIConnection(ctrl)
+	res := px.NewMockBatchResults(ctrl)
+	rows1 := px.NewMockRows(ctrl)
+	rows2 := px.NewMockRows(ctrl)
+	row := px.NewMockRow(ctrl)
+
+	errRows := errors.New("rows failed")
+	batch := &pgx.Batch{} //nolint:exhaustruct // test
+	batch.Queue("SELECT id FROM users")
+	batch.Queue("SELECT id FROM orders")
+	batch.Queue("SELECT count(*) FROM users")
+
+	con.EXPECT().InTransaction().Return(false)
+	con.EXPECT().SendBatch(gomock.Any(), batch).Return(res)
+
+	// the first rows are closed by the caller
+	res.EXPECT().Query().Return(rows1, nil)
+	rows1.EXPECT().Close().Times(2)
+	rows1.EXPECT().CommandTag().Return(pgconn.NewCommandTag("SELECT 3"))
+	rows1.EXPECT().Err().Return(nil)
+
+	// the second rows fail and are not closed by the caller
+	res.EXPECT().Query().Return(rows2, nil)
+	rows2.EXPECT().Close()
+	rows2.EXPECT().CommandTag().Return(pgconn.NewCommandTag("SELECT 1"))
+	rows2.EXPECT().Err().Return(errRows)
+
+	res.EXPECT().QueryRow().Return(row)
+	row.EXPECT().Scan(gomock.Any()).Return(nil)
+	res.EXPECT().Close().Return(nil)
+
+	var info *QueryInfo
+	ic := Intercept(con, InterceptorFuncs{
+		BeforeFunc: nil,
+		AfterFunc: func(_ context.Context, q *QueryInfo) {
+			info = q
+		},
+	})
+
+	br := ic.SendBatch(context.Background(), batch)
+	r, err := br.Query()
+	require.NoError(t, err)
+	r.Close()
+
+	_, err = br.Query()
+	require.NoError(t, err)
+
+	var n int
+	require.NoError(t, br.QueryRow().Scan(&n))
+	require.NoError(t, br.Close())
+
+	require.Equal(t, int64(5), info.RowsAffected)
+	require.ErrorIs(t, info.Err, errRows)
+}
+
+func TestIntercept_NoInterceptors(t *testing.T) {
+	t.Parallel()
+
+	con := NewMockIConnection(gomock.NewController(t))
+	require.Same(t, con, Intercept(con))
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	errBlocked := errors.New("blocked")
+
+	var calls []string
+	//nolint:exhaustruct // test
+	q := &QueryInfo{Command: CommandCommit, SQL: "COMMIT", InTransaction: true}
+
+	err := Run(context.Background(), []Interceptor{recordInterceptor(t, "first", &calls)}, q,
+		func(ctx context.Context) (int64, error) {
+			require.Equal(t, "first", ctx.Value(interceptorCtxKey{}))
+			return 0, nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, []string{"before first", "after first"}, calls)
+	require.False(t, q.Start.IsZero())
+
+	block := InterceptorFuncs{
+		BeforeFunc: func(ctx context.Context, _ *QueryInfo) (context.Context, error) {
+			return ctx, errBlocked
+		},
+		AfterFunc: nil,
+	}
+	err = Run(context.Background(), []Interceptor{block}, q, func(context.Context) (int64, error) {
+		require.Fail(t, "must not be called")
+		return 0, nil
+	})
+	require.ErrorIs(t, err, errBlocked)
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/n-r-w/pgh/v2/txmgr"
+	sq "github.com/n-r-w/squirrel"
+)
+
+// cursorCounter is used to generate unique cursor names within a session.
+var cursorCounter atomic.Uint64 //nolint:gochecknoglobals // unique names
+
+// ErrInvalidChunkSize chunk size must be greater than zero.
+var ErrInvalidChunkSize = errors.New("chunk size must be greater than zero")
+
+// StreamCursor executes query using a server-side cursor and passes rows to f in chunks of chunkSize.
+// Only one chunk is kept in memory, so it is suitable for walking through large tables.
+// Must be called within a transaction started by db (cursors live until the end of the transaction),
+// otherwise txmgr.ErrNoTransaction is returned.
+// The cursor is closed when all rows are read, f returns an error, the context is canceled or f panics.
+// The chunk slice is not reused, so f can keep it.
+func StreamCursor[T any](
+	ctx context.Context,
+	db *PxDB,
+	query sq.Sqlizer,
+	chunkSize int,
+	f func(ctx context.Context, chunk []T) error,
+) (err error) {
+	if chunkSize <= 0 {
+		return fmt.Errorf("StreamCursor: %w", ErrInvalidChunkSize)
+	}
+
+	if _, ok := txFromContext(ctx); !ok {
+		return fmt.Errorf("StreamCursor: %w", txmgr.ErrNoTransaction)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("StreamCursor to sql: %w", err)
+	}
+
+	con := db.Connection(ctx)
+	name := fmt.Sprintf("pgh_cursor_%d", cursorCounter.Add(1))
+
+	if _, err = px.ExecPlain(ctx, con, fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", name, sql), args); err != nil {
+		return fmt.Errorf("StreamCursor: %w", err)
+	}
+
+	defer func() {
+		// the context can be canceled already, but the cursor must be closed anyway.
+		// If the transaction is aborted by an error, CLOSE fails, but the cursor is closed with the transaction.
+		_, errClose := px.ExecPlain(context.WithoutCancel(ctx), con, "CLOSE "+name, nil)
+		if err == nil && errClose != nil {
+			err = fmt.Errorf("StreamCursor: %w", errClose)
+		}
+	}()
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", chunkSize, name)
+	for {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		var chunk []T
+		if err = px.SelectPlain(ctx, con, fetch, &chunk, nil); err != nil {
+			return fmt.Errorf("StreamCursor: %w", err)
+		}
+
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		if err = f(ctx, chunk); err != nil {
+			return err
+		}
+
+		if len(chunk) < chunkSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/txmgr"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamCursor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, informer := testdock.GetPgxPool(t, testdock.DefaultPostgresDSN)
+
+	pxDB := New(
+		WithName("cursor"),
+		WithDSN(informer.DSN()),
+	)
+
+	ctxStart, cancelStart := context.WithTimeout(ctx, 5*time.Second)
+	t.Cleanup(cancelStart)
+	require.NoError(t, pxDB.Start(ctxStart))
+	t.Cleanup(func() {
+		_ = pxDB.Stop(ctx)
+	})
+
+	_, err := pxDB.Connection(ctx).Exec(ctx,
+		"CREATE TABLE test_cursor AS SELECT id FROM generate_series(1, 25) AS id")
+	require.NoError(t, err)
+
+	query := pgh.Builder().Select("id").From("test_cursor").Where("id > ?", 0).OrderBy("id")
+
+	// without transaction
+	err = StreamCursor(ctx, pxDB, query, 10, func(context.Context, []int) error { return nil })
+	require.ErrorIs(t, err, txmgr.ErrNoTransaction)
+
+	tm := txmgr.New(pxDB, pxDB)
+
+	require.NoError(t, tm.Begin(ctx, func(ctxTr context.Context) error {
+		var (
+			chunks [][]int
+			ids    []int
+		)
+		require.NoError(t, StreamCursor(ctxTr, pxDB, query, 10, func(_ context.Context, chunk []int) error {
+			chunks = append(chunks, chunk)
+			ids = append(ids, chunk...)
+			return nil
+		}))
+		require.Len(t, chunks, 3)
+		require.Len(t, chunks[2], 5)
+		require.Len(t, ids, 25)
+		require.Equal(t, 25, ids[24])
+
+		// the callback error stops fetching, the cursor is closed
+		errStop := errors.New("stop")
+		calls := 0
+		require.ErrorIs(t, StreamCursor(ctxTr, pxDB, query, 10, func(context.Context, []int) error {
+			calls++
+			return errStop
+		}), errStop)
+		require.Equal(t, 1, calls)
+
+		// the cursor is closed after panic
+		require.Panics(t, func() {
+			_ = StreamCursor(ctxTr, pxDB, query, 10, func(context.Context, []int) error {
+				panic("test")
+			})
+		})
+
+		var open int
+		require.NoError(t, pxDB.Connection(ctxTr).QueryRow(ctxTr, "SELECT COUNT(*) FROM pg_cursors").Scan(&open))
+		require.Zero(t, open)
+
+		return nil
+	}, txmgr.WithTransactionMode(txmgr.TxReadOnly)))
+}
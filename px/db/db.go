@@ -4,6 +4,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/n-r-w/bootstrap"
 	"github.com/n-r-w/ctxlog"
@@ -20,11 +21,21 @@ type PxDB struct {
 	restartPolicy  []backoff.RetryOption
 	dsn            string
 	logQueries     bool
+	queryTimeout   time.Duration
+	interceptors   []conn.Interceptor
+	argPolicy      *ArgPolicy
 	afterStartFunc func(context.Context, *PxDB) error
 
+	// slow query log, see WithSlowQueryLog
+	slowQueryThreshold       time.Duration
+	slowQueryExplainInterval time.Duration
+	slowQuery                *slowQueryInterceptor
+
 	config *pgxpool.Config
 	pool   *pgxpool.Pool
 
+	replicas replicaSet
+
 	logger ctxlog.ILogger
 
 	// testHookAfterAcquire is an internal hook for tests only.
@@ -37,14 +48,23 @@ var _ bootstrap.IService = (*PxDB)(nil)
 // New creates a new instance of PxDB.
 func New(opt ...Option) *PxDB {
 	p := &PxDB{ //nolint:exhaustruct // default values
-		name:   "pgdb",
-		logger: ctxlog.NewStubWrapper(),
+		name:      "pgdb",
+		logger:    ctxlog.NewStubWrapper(),
+		argPolicy: NewArgPolicy(),
+		replicas: replicaSet{ //nolint:exhaustruct // default values
+			balancer:            ReplicaRoundRobin,
+			healthCheckInterval: defaultReplicaHealthCheckInterval,
+		},
 	}
 
 	for _, o := range opt {
 		o(p)
 	}
 
+	if p.slowQueryThreshold > 0 {
+		p.slowQuery = newSlowQueryInterceptor(p)
+	}
+
 	return p
 }
 
@@ -82,11 +102,19 @@ func (p *PxDB) Start(ctx context.Context) (err error) {
 
 	p.logger.Debug(ctx, "database connection established", "database", p.name)
 
+	if err = p.startReplicas(ctx); err != nil {
+		pool.Close()
+		p.pool = nil
+		return err
+	}
+
 	return nil
 }
 
 // Stop stops the service.
 func (p *PxDB) Stop(_ context.Context) error {
+	p.stopReplicas()
+
 	if p.pool != nil {
 		p.pool.Close()
 	}
@@ -105,19 +133,21 @@ func (p *PxDB) Info() bootstrap.Info {
 // Connection extracts transaction/pool from context and returns database interface implementation.
 // Use only at repository level. Returns IConnection interface implementation.
 func (p *PxDB) Connection(ctx context.Context, opt ...conn.ConnectionOption) conn.IConnection {
-	opts := &conn.ConnectionOptionData{LogQueries: false}
+	opts := &conn.ConnectionOptionData{LogQueries: false, Primary: false, StatementTimeout: 0, LockTimeout: 0}
 	for _, o := range opt {
 		o(opts)
 	}
 
 	it, ok := txFromContext(ctx)
 	if !ok {
-		return newDatabaseWrapperNoTran(p, opts.LogQueries || p.logQueries)
+		return newDatabaseWrapperNoTran(p, opts.LogQueries || p.logQueries, opts.Primary,
+			opts.StatementTimeout, opts.LockTimeout)
 	}
 
 	if p != it.db {
 		panic("invalid DB") // this should never happen
 	}
 
-	return newDatabaseWrapperWithTran(p, it.tx, it.opts, opts.LogQueries || p.logQueries)
+	return newDatabaseWrapperWithTran(p, it.tx, it.opts, opts.LogQueries || p.logQueries,
+		opts.StatementTimeout, opts.LockTimeout)
 }
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -151,3 +152,115 @@ func TestPxDB(t *testing.T) {
 	t.Cleanup(cancelStop)
 	require.NoError(t, pgdbImpl.Stop(ctxStop))
 }
+
+func TestPxDB_Interceptors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, informer := testdock.GetPgxPool(t, testdock.DefaultPostgresDSN)
+
+	errBlocked := errors.New("DROP is not allowed")
+
+	var infos []conn.QueryInfo
+	pgdbImpl := New(
+		WithDSN(informer.DSN()),
+		WithLogQueries(),
+		WithInterceptors(conn.InterceptorFuncs{
+			BeforeFunc: func(ctx context.Context, q *conn.QueryInfo) (context.Context, error) {
+				if strings.HasPrefix(q.SQL, "DROP") {
+					return ctx, errBlocked
+				}
+				q.SQL = strings.ReplaceAll(q.SQL, "$table", "interceptor_test")
+				return ctx, nil
+			},
+			AfterFunc: func(_ context.Context, q *conn.QueryInfo) {
+				infos = append(infos, *q)
+			},
+		}),
+	)
+
+	require.NoError(t, pgdbImpl.Start(ctx))
+	t.Cleanup(func() { _ = pgdbImpl.Stop(ctx) })
+
+	require.NoError(t, pgdbImpl.Begin(ctx, func(ctxTr context.Context) error {
+		con := pgdbImpl.Connection(ctxTr)
+
+		if _, err := con.Exec(ctxTr, "CREATE TABLE $table (id int)"); err != nil {
+			return err
+		}
+
+		if _, err := con.Exec(ctxTr, "INSERT INTO $table (id) VALUES (1), (2)"); err != nil {
+			return err
+		}
+
+		_, err := con.Exec(ctxTr, "DROP TABLE $table")
+		require.ErrorIs(t, err, errBlocked)
+
+		return nil
+	}, txmgr.Options{})) //nolint:exhaustruct // external type, zero values are acceptable defaults
+
+	commands := make([]string, 0, len(infos))
+	for _, info := range infos {
+		commands = append(commands, info.Command)
+	}
+	require.Equal(t, []string{
+		conn.CommandBegin, conn.CommandExec, conn.CommandExec, conn.CommandExec, conn.CommandCommit,
+	}, commands)
+
+	require.Equal(t, "INSERT INTO interceptor_test (id) VALUES (1), (2)", infos[2].SQL)
+	require.True(t, infos[2].InTransaction)
+	require.Equal(t, int64(2), infos[2].RowsAffected)
+	require.ErrorIs(t, infos[3].Err, errBlocked)
+	require.NoError(t, infos[4].Err)
+}
+
+func TestPxDB_InterceptorBlocksCommit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, informer := testdock.GetPgxPool(t, testdock.DefaultPostgresDSN)
+
+	errBlocked := errors.New("commit is not allowed")
+
+	var commands []string
+	pgdbImpl := New(
+		WithDSN(informer.DSN()),
+		WithInterceptors(conn.InterceptorFuncs{
+			BeforeFunc: func(ctx context.Context, q *conn.QueryInfo) (context.Context, error) {
+				if q.Command == conn.CommandCommit {
+					return ctx, errBlocked
+				}
+				return ctx, nil
+			},
+			AfterFunc: func(_ context.Context, q *conn.QueryInfo) {
+				commands = append(commands, q.Command)
+			},
+		}),
+	)
+
+	require.NoError(t, pgdbImpl.Start(ctx))
+	t.Cleanup(func() { _ = pgdbImpl.Stop(ctx) })
+
+	rolledBack := false
+	err := pgdbImpl.Begin(ctx, func(ctxTr context.Context) error {
+		if err := txmgr.OnRollback(ctxTr, func(context.Context) { rolledBack = true }); err != nil {
+			return err
+		}
+		_, err := pgdbImpl.Connection(ctxTr).Exec(ctxTr, "CREATE TABLE blocked_commit_test (id int)")
+		return err
+	}, txmgr.Options{}) //nolint:exhaustruct // external type, zero values are acceptable defaults
+	require.ErrorIs(t, err, errBlocked)
+
+	// the blocked commit is followed by an explicit rollback
+	require.Equal(t, []string{
+		conn.CommandBegin, conn.CommandExec, conn.CommandCommit, conn.CommandRollback,
+	}, commands)
+	require.True(t, rolledBack)
+
+	var exists bool
+	require.NoError(t, pgdbImpl.Connection(ctx).QueryRow(ctx,
+		"SELECT to_regclass('blocked_commit_test') IS NOT NULL").Scan(&exists))
+	require.False(t, exists)
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/n-r-w/pgh/v2/txmgr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTransactionHooks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	//nolint:exhaustruct // zero values are acceptable defaults for test
+	tr := newTransaction(&PxDB{}, px.NewMockTx(mc), txmgr.Options{})
+	tCtx := tr.toContext(ctx)
+
+	var calls []string
+	hook := func(name string) func(context.Context) {
+		return func(ctx context.Context) {
+			// hooks are called with a context without transaction even if the finished one is passed
+			require.False(t, (&PxDB{}).InTransaction(ctx)) //nolint:exhaustruct // test
+			require.ErrorIs(t, txmgr.OnCommit(ctx, func(context.Context) {}), txmgr.ErrNoTransaction)
+			calls = append(calls, name)
+		}
+	}
+
+	require.NoError(t, txmgr.OnCommit(tCtx, hook("commit 1")))
+	require.NoError(t, txmgr.OnRollback(tCtx, hook("rollback 1")))
+
+	// released savepoint passes its callbacks to the outer transaction
+	//nolint:exhaustruct // zero values are acceptable defaults for test
+	released := newTransaction(&PxDB{}, px.NewMockTx(mc), txmgr.Options{})
+	require.NoError(t, txmgr.OnCommit(released.toContext(tCtx), hook("commit 2")))
+	released.moveHooks(tr)
+
+	// rolled back savepoint runs its rollback callbacks immediately and discards commit callbacks
+	//nolint:exhaustruct // zero values are acceptable defaults for test
+	rolledBack := newTransaction(&PxDB{}, px.NewMockTx(mc), txmgr.Options{})
+	require.NoError(t, txmgr.OnCommit(rolledBack.toContext(tCtx), hook("commit 3")))
+	rolledBackCtx := rolledBack.toContext(tCtx)
+	require.NoError(t, txmgr.OnRollback(rolledBackCtx, hook("rollback 3")))
+	rolledBack.runRollbackHooks(rolledBackCtx)
+	require.Equal(t, []string{"rollback 3"}, calls)
+
+	// callbacks are called once in registration order
+	tr.runCommitHooks(tCtx)
+	tr.runCommitHooks(tCtx)
+	tr.runRollbackHooks(tCtx)
+	require.Equal(t, []string{"rollback 3", "commit 1", "commit 2"}, calls)
+
+	// context without transaction has no hooks
+	require.ErrorIs(t, txmgr.OnCommit(WithoutTransaction(tCtx), hook("no transaction")), txmgr.ErrNoTransaction)
+	require.ErrorIs(t, txmgr.OnRollback(ctx, hook("no transaction")), txmgr.ErrNoTransaction)
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/n-r-w/pgh/v2/txmgr"
+)
+
+const (
+	// minLockRetryInterval initial interval between attempts to acquire an advisory lock with timeout.
+	minLockRetryInterval = 10 * time.Millisecond
+	// maxLockRetryInterval maximum interval between attempts to acquire an advisory lock with timeout.
+	maxLockRetryInterval = 200 * time.Millisecond
+)
+
+var _ txmgr.ITransactionLocker = (*PxDB)(nil)
+
+// AcquireLocks acquires advisory locks for opts.LockKeys in the transaction stored in ctx.
+// Implements txmgr.ITransactionLocker.
+func (p *PxDB) AcquireLocks(ctx context.Context, opts txmgr.Options) error {
+	it, ok := txFromContext(ctx)
+	if !ok {
+		return errors.New("failed to acquire advisory locks: transaction is not started")
+	}
+
+	return acquireAdvisoryLocks(ctx, it.tx, opts)
+}
+
+// acquireAdvisoryLocks acquires transaction-level advisory locks for opts.LockKeys.
+// Keys are sorted to avoid deadlocks between transactions that lock the same set of keys.
+// Locks are released automatically at the end of the transaction.
+func acquireAdvisoryLocks(ctx context.Context, tx pgx.Tx, opts txmgr.Options) error {
+	if len(opts.LockKeys) == 0 {
+		return nil
+	}
+
+	keys := slices.Clone(opts.LockKeys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	for _, key := range keys {
+		var err error
+		if opts.LockTimeout > 0 {
+			err = tryAdvisoryLock(ctx, tx, key, opts.LockTimeout)
+		} else {
+			_, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", key)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to acquire advisory lock %d: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// tryAdvisoryLock tries to acquire an advisory lock until timeout expires.
+// Unlike canceling pg_advisory_xact_lock, an unsuccessful attempt doesn't abort the transaction.
+func tryAdvisoryLock(ctx context.Context, tx pgx.Tx, key int64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	interval := minLockRetryInterval
+
+	for {
+		var locked bool
+		if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", key).Scan(&locked); err != nil {
+			return err
+		}
+
+		if locked {
+			return nil
+		}
+
+		wait := min(interval, time.Until(deadline))
+		if wait <= 0 {
+			return txmgr.ErrLockTimeout
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		interval = min(interval*2, maxLockRetryInterval) //nolint:mnd // exponential growth
+	}
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/pgh/v2/txmgr"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPxDB_AdvisoryLock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, informer := testdock.GetPgxPool(t, testdock.DefaultPostgresDSN)
+
+	pxDB := New(
+		WithName("advisory-lock"),
+		WithDSN(informer.DSN()),
+	)
+
+	ctxStart, cancelStart := context.WithTimeout(ctx, 5*time.Second)
+	t.Cleanup(cancelStart)
+	require.NoError(t, pxDB.Start(ctxStart))
+	t.Cleanup(func() {
+		_ = pxDB.Stop(ctx)
+	})
+
+	tm := txmgr.New(pxDB, pxDB)
+
+	var (
+		key      = txmgr.LockKey("order:42")
+		otherKey = txmgr.LockKey("order:43")
+	)
+
+	require.NoError(t, tm.Begin(ctx, func(ctxTr context.Context) error {
+		// nested call acquires another key in the same transaction
+		require.NoError(t, tm.Begin(ctxTr, func(_ context.Context) error {
+			return nil
+		}, txmgr.WithLock(otherKey)))
+
+		// independent transaction can't acquire the keys held by the current one
+		for _, k := range []int64{key, otherKey} {
+			errLock := tm.Begin(pxDB.WithoutTransaction(ctxTr), func(_ context.Context) error {
+				return nil
+			}, txmgr.WithLock(k), txmgr.WithLockTimeout(100*time.Millisecond))
+			require.ErrorIs(t, errLock, txmgr.ErrLockTimeout)
+		}
+
+		return nil
+	}, txmgr.WithLock(key)))
+
+	// locks are released after commit
+	require.NoError(t, tm.Begin(ctx, func(_ context.Context) error {
+		return nil
+	}, txmgr.WithLock(key, otherKey), txmgr.WithLockTimeout(100*time.Millisecond)))
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewLogInterceptor creates an interceptor that logs queries: successful at the debug level, failed at the error level.
+// It is used by PxDB for WithLogQueries and conn.WithLogQueries and can be passed to the sharded DBs.
+// name is logged as the database name. Arguments are written according to policy, nil policy writes them as is.
+func NewLogInterceptor(logger ctxlog.ILogger, name string, policy *ArgPolicy) conn.Interceptor {
+	return &logInterceptor{logger: logger, name: name, policy: policy}
+}
+
+// logInterceptor logs queries after execution.
+type logInterceptor struct {
+	logger ctxlog.ILogger
+	name   string
+	policy *ArgPolicy
+}
+
+// Before does nothing.
+func (l *logInterceptor) Before(ctx context.Context, _ *conn.QueryInfo) (context.Context, error) {
+	return ctx, nil
+}
+
+// After logs the query.
+func (l *logInterceptor) After(ctx context.Context, q *conn.QueryInfo) {
+	attrs := []any{
+		"database", l.name,
+		"command", q.Command,
+		"latency", q.Duration,
+		"args", l.policy.Args(q.SQL, q.Args),
+	}
+
+	if query := logQueryText(q, l.policy); query != "" {
+		attrs = append(attrs, "query", query)
+	}
+
+	spanContext := trace.SpanFromContext(ctx).SpanContext()
+	if spanContext.TraceID().IsValid() {
+		attrs = append(attrs, "trace_id", spanContext.TraceID().String())
+	}
+
+	if q.Err != nil {
+		attrs = append(attrs, "error", q.Err)
+		l.logger.Error(ctx, "dbquery", attrs...)
+	} else {
+		l.logger.Debug(ctx, "dbquery", attrs...)
+	}
+}
+
+// logQueryText returns the query text for the log.
+func logQueryText(q *conn.QueryInfo, policy *ArgPolicy) string {
+	switch q.Command {
+	case conn.CommandCopyFrom:
+		return fmt.Sprintf("COPY %s (%s)", q.Table.Sanitize(), strings.Join(q.Columns, ", "))
+	case conn.CommandSendBatch:
+		return logBatchText(q, policy)
+	default:
+		return q.SQL
+	}
+}
+
+// logBatchText returns the text of the first queries of the batch with arguments written according to policy.
+func logBatchText(q *conn.QueryInfo, policy *ArgPolicy) string {
+	const batchSizeLogLimit = 10
+
+	if q.Batch == nil {
+		return ""
+	}
+
+	var queries strings.Builder
+	for i, bq := range q.Batch.QueuedQueries {
+		if i > batchSizeLogLimit {
+			_, _ = queries.WriteString("...")
+			break
+		}
+		// [SELECT * FROM users WHERE id IN ($1,$2); ARGS: 2,3]
+		_, _ = queries.WriteString("[")
+		_, _ = queries.WriteString(bq.SQL)
+		_, _ = queries.WriteString("; ARGS: ")
+		for j, arg := range policy.Args(bq.SQL, bq.Arguments) {
+			if j > 0 {
+				_, _ = queries.WriteString(",")
+			}
+			_, _ = queries.WriteString(fmt.Sprintf("%v", arg))
+		}
+		_, _ = queries.WriteString("]")
+	}
+
+	return queries.String()
+}
@@ -2,10 +2,13 @@ package db
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/cenkalti/backoff/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
 )
 
 // Option option for PxDB.
@@ -55,6 +58,52 @@ func WithLogQueries() Option {
 	}
 }
 
+// WithArgPolicy sets how query arguments are written to the query log and the slow query log:
+// redaction of sensitive values and truncation of long ones. Default is NewArgPolicy() that only truncates values.
+func WithArgPolicy(policy *ArgPolicy) Option {
+	return func(p *PxDB) {
+		p.argPolicy = policy
+	}
+}
+
+// WithSlowQueryLog logs queries executed through Connection longer than threshold at the warn level
+// with the query text, arguments written according to WithArgPolicy and latency. For Query the latency includes reading the rows.
+func WithSlowQueryLog(threshold time.Duration) Option {
+	return func(p *PxDB) {
+		p.slowQueryThreshold = threshold
+	}
+}
+
+// WithSlowQueryExplain attaches the plan of a slow query obtained with EXPLAIN (FORMAT JSON) to its log entry.
+// The plan is obtained in the background on a separate connection, not more often than once per interval.
+// If WithArgPolicy redacts any argument of the query, the generic plan is obtained with EXPLAIN (GENERIC_PLAN),
+// which requires PostgreSQL 16+, so redacted values are not inlined into the plan.
+// Works only together with WithSlowQueryLog.
+func WithSlowQueryExplain(interval time.Duration) Option {
+	return func(p *PxDB) {
+		p.slowQueryExplainInterval = interval
+	}
+}
+
+// WithInterceptors adds interceptors called for each query executed through Connection.
+// Before hooks are called in the order of interceptors, After hooks in the reverse order.
+// The slow query log and the query logger enabled by WithLogQueries are called after them.
+func WithInterceptors(interceptors ...conn.Interceptor) Option {
+	return func(p *PxDB) {
+		p.interceptors = append(p.interceptors, interceptors...)
+	}
+}
+
+// WithQueryTimeout sets the default client-side timeout of each query executed through Connection,
+// with or without transaction. The query context is canceled after the timeout.
+// conn.WithStatementTimeout overrides it for a connection. Use txmgr.WithStatementTimeout
+// or conn.WithStatementTimeout to limit queries on the server as well.
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(p *PxDB) {
+		p.queryTimeout = timeout
+	}
+}
+
 // WithAfterStartFunc sets a function that will be called after successful service start.
 func WithAfterStartFunc(f func(context.Context, *PxDB) error) Option {
 	return func(p *PxDB) {
@@ -68,3 +117,64 @@ func WithLogger(logger ctxlog.ILogger) Option {
 		p.logger = logger
 	}
 }
+
+// WithReplicaDSN adds read replicas by DSN.
+// Non-transactional reads and read-only transactions are routed to healthy replicas, everything else goes to the primary.
+func WithReplicaDSN(dsn ...string) Option {
+	return func(p *PxDB) {
+		for _, d := range dsn {
+			p.replicas.replicas = append(p.replicas.replicas, &replica{dsn: d}) //nolint:exhaustruct // pool is created on start
+		}
+	}
+}
+
+// WithReplicaConfig adds read replicas by connection pool configuration.
+func WithReplicaConfig(cfg ...*pgxpool.Config) Option {
+	return func(p *PxDB) {
+		for _, c := range cfg {
+			p.replicas.replicas = append(p.replicas.replicas, &replica{config: c}) //nolint:exhaustruct // pool is created on start
+		}
+	}
+}
+
+// WithReplicaPool adds read replicas by existing connection pools. The pools are not closed on Stop.
+func WithReplicaPool(pool ...*pgxpool.Pool) Option {
+	return func(p *PxDB) {
+		for _, pl := range pool {
+			r := &replica{dsn: "", config: nil} //nolint:exhaustruct // atomic fields
+			r.pool.Store(pl)
+			p.replicas.replicas = append(p.replicas.replicas, r)
+		}
+	}
+}
+
+// WithReplicaBalancer sets the strategy for choosing a read replica. Default is ReplicaRoundRobin.
+func WithReplicaBalancer(balancer ReplicaBalancer) Option {
+	return func(p *PxDB) {
+		p.replicas.balancer = balancer
+	}
+}
+
+// WithReplicaPrimaryFunctions sets functions that modify data, SELECT queries calling them go to the primary.
+// Names are case-insensitive and without schema. Built-in functions such as nextval, setval, pg_notify and
+// advisory locks are always routed to the primary. Use conn.WithPrimary for other queries that must not
+// go to a replica.
+func WithReplicaPrimaryFunctions(names ...string) Option {
+	return func(p *PxDB) {
+		if p.replicas.primaryFunctions == nil {
+			p.replicas.primaryFunctions = make(map[string]struct{}, len(names))
+		}
+		for _, name := range names {
+			p.replicas.primaryFunctions[strings.ToLower(name)] = struct{}{}
+		}
+	}
+}
+
+// WithReplicaHealthCheck sets the interval of replica health checks. Default is 5 seconds.
+func WithReplicaHealthCheck(interval time.Duration) Option {
+	return func(p *PxDB) {
+		if interval > 0 {
+			p.replicas.healthCheckInterval = interval
+		}
+	}
+}
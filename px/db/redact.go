@@ -0,0 +1,311 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// RedactedArg is logged instead of redacted query arguments.
+	RedactedArg = "[REDACTED]"
+	// DefaultArgMaxLen default maximum length of a logged query argument.
+	DefaultArgMaxLen = 256
+)
+
+// ArgInfo information about a query argument passed to Redactor.
+type ArgInfo struct {
+	// SQL query text.
+	SQL string
+	// Position of the argument in the query: $1 is 1.
+	Position int
+	// Column the argument is assigned to or compared with in the query, in lower case.
+	// Empty if the column is not detected.
+	Column string
+	// Value of the argument.
+	Value any
+}
+
+// Redactor returns the value to log instead of the argument. It must return arg.Value for arguments
+// that don't need redaction.
+type Redactor func(arg ArgInfo) any
+
+// ArgPolicy defines how query arguments are written to logs and telemetry:
+// sensitive values are redacted and long values are truncated. Create it with NewArgPolicy.
+// The zero policy writes arguments as is.
+type ArgPolicy struct {
+	positions map[int]struct{}
+	columns   map[string]struct{}
+	redactors []Redactor
+	maxLen    int
+}
+
+// ArgPolicyOption option for ArgPolicy.
+type ArgPolicyOption func(*ArgPolicy)
+
+// WithRedactedPositions redacts arguments at positions: $1 is 1.
+func WithRedactedPositions(positions ...int) ArgPolicyOption {
+	return func(p *ArgPolicy) {
+		for _, pos := range positions {
+			p.positions[pos] = struct{}{}
+		}
+	}
+}
+
+// WithRedactedColumns redacts arguments assigned to or compared with columns, case-insensitive.
+// Columns are detected in INSERT ... (columns) VALUES (...), in comparisons "column op $n" and "$n op column"
+// with =, <>, !=, <, <=, >, >=, [NOT] LIKE and [NOT] ILIKE, e.g. in UPDATE ... SET and WHERE,
+// in "column op ANY($n)" and in "column [NOT] IN ($n, ...)". Arguments of other expressions, e.g. function calls,
+// are not detected, use WithRedactedPositions or WithRedactor for them.
+func WithRedactedColumns(columns ...string) ArgPolicyOption {
+	return func(p *ArgPolicy) {
+		for _, c := range columns {
+			p.columns[strings.ToLower(c)] = struct{}{}
+		}
+	}
+}
+
+// WithRedactor adds a function that redacts arguments. Functions are called in the order they are added
+// for arguments not redacted by position or column.
+func WithRedactor(f Redactor) ArgPolicyOption {
+	return func(p *ArgPolicy) {
+		p.redactors = append(p.redactors, f)
+	}
+}
+
+// WithArgMaxLen sets the maximum length of a logged argument. Longer strings and JSON are truncated,
+// binary data and other values are replaced by their size or truncated text. Zero disables truncation.
+// Default is DefaultArgMaxLen.
+func WithArgMaxLen(maxLen int) ArgPolicyOption {
+	return func(p *ArgPolicy) {
+		p.maxLen = maxLen
+	}
+}
+
+// NewArgPolicy creates ArgPolicy. Without options arguments are only truncated to DefaultArgMaxLen.
+func NewArgPolicy(opts ...ArgPolicyOption) *ArgPolicy {
+	p := &ArgPolicy{
+		positions: make(map[int]struct{}),
+		columns:   make(map[string]struct{}),
+		redactors: nil,
+		maxLen:    DefaultArgMaxLen,
+	}
+
+	for _, o := range opts {
+		o(p)
+	}
+
+	return p
+}
+
+// Args returns arguments of the query sql to write to logs or telemetry. args are not modified.
+// A nil policy returns args as is.
+func (p *ArgPolicy) Args(sql string, args []any) []any {
+	if p == nil || len(args) == 0 {
+		return args
+	}
+
+	var columns map[int]string
+	if len(p.columns) > 0 || len(p.redactors) > 0 {
+		columns = argColumns(sql)
+	}
+
+	res := make([]any, len(args))
+	for i, value := range args {
+		arg := ArgInfo{SQL: sql, Position: i + 1, Column: columns[i+1], Value: value}
+		redacted, _ := p.redact(arg)
+		res[i] = p.truncate(redacted)
+	}
+
+	return res
+}
+
+// redacts returns true if the policy redacts any of the arguments of the query sql.
+// Values of such arguments must not be written anywhere, e.g. with an EXPLAIN plan.
+func (p *ArgPolicy) redacts(sql string, args []any) bool {
+	if p == nil || len(args) == 0 || (len(p.positions) == 0 && len(p.columns) == 0 && len(p.redactors) == 0) {
+		return false
+	}
+
+	columns := argColumns(sql)
+	for i, value := range args {
+		if _, redacted := p.redact(ArgInfo{SQL: sql, Position: i + 1, Column: columns[i+1], Value: value}); redacted {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redact returns the redacted value of the argument and true if it differs from the original value.
+func (p *ArgPolicy) redact(arg ArgInfo) (any, bool) {
+	if _, ok := p.positions[arg.Position]; ok {
+		return RedactedArg, true
+	}
+
+	if _, ok := p.columns[arg.Column]; ok && arg.Column != "" {
+		return RedactedArg, true
+	}
+
+	if len(p.redactors) == 0 {
+		return arg.Value, false
+	}
+
+	original := arg.Value
+	value := arg.Value
+	for _, r := range p.redactors {
+		arg.Value = value
+		value = r(arg)
+	}
+
+	return value, !reflect.DeepEqual(value, original)
+}
+
+// truncate limits the length of the value.
+func (p *ArgPolicy) truncate(value any) any {
+	if p.maxLen <= 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return value
+	case string:
+		return truncateString(v, p.maxLen)
+	case json.RawMessage:
+		return truncateString(string(v), p.maxLen)
+	case []byte:
+		return fmt.Sprintf("<%d bytes>", len(v))
+	default:
+		if s := fmt.Sprintf("%v", v); len(s) > p.maxLen {
+			return truncateString(s, p.maxLen)
+		}
+		return value
+	}
+}
+
+// truncateString truncates s to maxLen bytes without breaking UTF-8 characters.
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+
+	return strings.ToValidUTF8(s[:maxLen], "") + fmt.Sprintf("...(%d bytes)", len(s))
+}
+
+var (
+	// column op $n, column op ANY($n), the column can be qualified or quoted
+	argAssignRe = regexp.MustCompile(`(?i)([A-Za-z_][A-Za-z0-9_$]*)"?\s*` + argCompareOp +
+		`\s*(?:(?:ANY|ALL|SOME)\s*\(\s*)?\$(\d+)`)
+	// $n op column, the column can be qualified or quoted; a function call after op is not a column
+	argReverseRe = regexp.MustCompile(`(?i)\$(\d+)\s*` + argCompareOp +
+		`\s*(?:"?[A-Za-z_][A-Za-z0-9_$]*"?\.)?"?([A-Za-z_][A-Za-z0-9_$]*)"?(\s*\()?`)
+	// column [NOT] IN ($n, ...)
+	argInRe = regexp.MustCompile(`(?i)([A-Za-z_][A-Za-z0-9_$]*)"?\s+(?:NOT\s+)?IN\s*\(([^()]*)\)`)
+	// $n anywhere in the text
+	argAnyPlaceholderRe = regexp.MustCompile(`\$(\d+)`)
+	// INSERT INTO table (columns) VALUES ...
+	argInsertRe = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+[^(]+\(([^)]*)\)\s*VALUES\s*(.*)$`)
+	// $n
+	argPlaceholderRe = regexp.MustCompile(`^\s*\$(\d+)\s*$`)
+)
+
+// argCompareOp comparison operators of argAssignRe and argReverseRe.
+const argCompareOp = `(?:=|<>|!=|<=|>=|<|>|(?:NOT\s+)?I?LIKE\b)`
+
+// argColumns detects columns of query arguments by their positions.
+func argColumns(sql string) map[int]string {
+	columns := make(map[int]string)
+
+	for _, m := range argAssignRe.FindAllStringSubmatch(sql, -1) {
+		if pos, err := strconv.Atoi(m[2]); err == nil {
+			columns[pos] = strings.ToLower(m[1])
+		}
+	}
+
+	for _, m := range argReverseRe.FindAllStringSubmatch(sql, -1) {
+		if m[3] != "" {
+			continue // function call
+		}
+		if pos, err := strconv.Atoi(m[1]); err == nil {
+			if _, ok := columns[pos]; !ok {
+				columns[pos] = strings.ToLower(m[2])
+			}
+		}
+	}
+
+	for _, m := range argInRe.FindAllStringSubmatch(sql, -1) {
+		for _, pm := range argAnyPlaceholderRe.FindAllStringSubmatch(m[2], -1) {
+			if pos, err := strconv.Atoi(pm[1]); err == nil {
+				columns[pos] = strings.ToLower(m[1])
+			}
+		}
+	}
+
+	m := argInsertRe.FindStringSubmatch(sql)
+	if m == nil {
+		return columns
+	}
+
+	names := strings.Split(m[1], ",")
+	for i := range names {
+		names[i] = strings.ToLower(strings.Trim(strings.TrimSpace(names[i]), `"`))
+	}
+
+	for _, row := range splitValues(m[2]) {
+		for i, item := range row {
+			if i >= len(names) {
+				break
+			}
+			if pm := argPlaceholderRe.FindStringSubmatch(item); pm != nil {
+				if pos, err := strconv.Atoi(pm[1]); err == nil {
+					columns[pos] = names[i]
+				}
+			}
+		}
+	}
+
+	return columns
+}
+
+// splitValues splits "($1, $2), ($3, f($4, $5))" into rows of top-level items.
+// Parsing stops at the first text after a row that is not a comma, e.g. ON CONFLICT or RETURNING.
+func splitValues(values string) [][]string {
+	var (
+		rows  [][]string
+		row   []string
+		depth int
+		start int
+	)
+
+	for i, c := range values {
+		switch c {
+		case '(':
+			depth++
+			if depth == 1 {
+				row = nil
+				start = i + 1
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				rows = append(rows, append(row, values[start:i]))
+			}
+		case ',':
+			if depth == 1 {
+				row = append(row, values[start:i])
+				start = i + 1
+			}
+		case ' ', '\t', '\n', '\r':
+		default:
+			if depth == 0 {
+				return rows
+			}
+		}
+	}
+
+	return rows
+}
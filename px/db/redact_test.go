@@ -0,0 +1,121 @@
+package db
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgPolicy_Redact(t *testing.T) {
+	t.Parallel()
+
+	p := NewArgPolicy(
+		WithRedactedPositions(4),
+		WithRedactedColumns("Password", "email"),
+		WithRedactor(func(arg ArgInfo) any {
+			if s, ok := arg.Value.(string); ok && strings.HasPrefix(s, "tok_") {
+				return "tok_***"
+			}
+			return arg.Value
+		}),
+	)
+
+	// INSERT columns and position
+	require.Equal(t,
+		[]any{"alice", RedactedArg, RedactedArg, RedactedArg, RedactedArg, RedactedArg},
+		p.Args(`INSERT INTO users (name, "email", password) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT DO NOTHING`,
+			[]any{"alice", "a@example.com", "secret", "bob", "b@example.com", "secret"}))
+
+	// UPDATE SET and WHERE, redactor function and position
+	require.Equal(t,
+		[]any{RedactedArg, "tok_***", int64(1), RedactedArg},
+		p.Args("UPDATE users SET password = $1, token = $2 WHERE u.id = $3 AND name = $4",
+			[]any{"secret", "tok_123", int64(1), "alice"}))
+
+	// nil policy returns args as is
+	var nilPolicy *ArgPolicy
+	args := []any{"secret"}
+	require.Equal(t, args, nilPolicy.Args("SELECT $1", args))
+
+	// redacted arguments are detected to avoid leaking them with EXPLAIN
+	require.True(t, p.redacts("SELECT * FROM users WHERE password = $1", []any{"secret"}))
+	require.True(t, p.redacts("SELECT * FROM users WHERE token = $1", []any{"tok_123"}))
+	require.False(t, p.redacts("SELECT * FROM users WHERE token = $1", []any{"abc"}))
+	require.False(t, NewArgPolicy().redacts("SELECT * FROM users WHERE password = $1", []any{"secret"}))
+	require.False(t, nilPolicy.redacts("SELECT * FROM users WHERE password = $1", []any{"secret"}))
+}
+
+func TestArgPolicy_Truncate(t *testing.T) {
+	t.Parallel()
+
+	const maxLen = 8
+
+	p := NewArgPolicy(WithArgMaxLen(maxLen))
+
+	long := strings.Repeat("a", 20)
+	require.Equal(t,
+		[]any{1, "short", "aaaaaaaa...(20 bytes)", "<3 bytes>", `{"a":1}`, "[1 2 3 4...(19 bytes)", nil, true},
+		p.Args("", []any{
+			1, "short", long, []byte{1, 2, 3}, json.RawMessage(`{"a":1}`),
+			[]int{1, 2, 3, 4, 5, 6, 7, 8, 9}, nil, true,
+		}))
+
+	// multi-byte characters are not broken
+	require.Equal(t, "привет...(24 bytes)", NewArgPolicy(WithArgMaxLen(13)).Args("", []any{"приветпривет"})[0])
+
+	// truncation is disabled
+	require.Equal(t, []any{long}, NewArgPolicy(WithArgMaxLen(0)).Args("", []any{long}))
+}
+
+func TestArgColumns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sql  string
+		want map[int]string
+	}{
+		{
+			sql:  "INSERT INTO t (a, b) VALUES ($1, $2), ($3, now()) RETURNING (SELECT 1 WHERE id = $4)",
+			want: map[int]string{1: "a", 2: "b", 3: "a", 4: "id"},
+		},
+		{
+			sql: "INSERT INTO t (a, b) VALUES ($1, $2) ON CONFLICT (a) DO UPDATE SET b = EXCLUDED.b, " +
+				"c = $3 WHERE t.d <> $4",
+			want: map[int]string{1: "a", 2: "b", 3: "c", 4: "d"},
+		},
+		{
+			sql:  "SELECT * FROM t WHERE id IN ($1) AND t.\"name\" = $2",
+			want: map[int]string{1: "id", 2: "name"},
+		},
+		{
+			sql:  "SELECT * FROM t WHERE token NOT IN ($1, $2::text) AND id = ANY($3) AND code = any ($4::text[])",
+			want: map[int]string{1: "token", 2: "token", 3: "id", 4: "code"},
+		},
+		{
+			sql:  "SELECT * FROM t WHERE a < $1 AND b <= $2 AND c > $3 AND d >= $4 AND e != $5 AND f <> $6",
+			want: map[int]string{1: "a", 2: "b", 3: "c", 4: "d", 5: "e", 6: "f"},
+		},
+		{
+			sql:  "SELECT * FROM t WHERE email LIKE $1 AND name not ilike $2",
+			want: map[int]string{1: "email", 2: "name"},
+		},
+		{
+			sql:  "SELECT * FROM t WHERE $1 = secret AND $2 < t.\"created_at\"",
+			want: map[int]string{1: "secret", 2: "created_at"},
+		},
+		{
+			sql:  "SELECT * FROM t WHERE $1 = ANY(tokens) AND $2 = lower(email) AND hash(password) = $3",
+			want: map[int]string{},
+		},
+		{
+			sql:  "SELECT $1",
+			want: map[int]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, argColumns(tt.sql), tt.sql)
+	}
+}
@@ -0,0 +1,298 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/n-r-w/pgh/v2/txmgr"
+)
+
+// ReplicaBalancer strategy for choosing a read replica.
+type ReplicaBalancer int
+
+const (
+	// ReplicaRoundRobin chooses healthy replicas in turn.
+	ReplicaRoundRobin ReplicaBalancer = iota
+	// ReplicaLeastConnections chooses the healthy replica with the least number of acquired connections.
+	ReplicaLeastConnections
+)
+
+// defaultReplicaHealthCheckInterval default interval of replica health checks.
+const defaultReplicaHealthCheckInterval = 5 * time.Second
+
+// replica read replica of the primary database.
+type replica struct {
+	dsn     string
+	config  *pgxpool.Config
+	pool    atomic.Pointer[pgxpool.Pool] // read concurrently by balancing, nil when stopped
+	owned   atomic.Bool                  // pool is created by PxDB and must be closed on Stop
+	healthy atomic.Bool
+	checked atomic.Bool
+}
+
+// replicaSet read replicas and their balancing.
+type replicaSet struct {
+	replicas            []*replica
+	balancer            ReplicaBalancer
+	healthCheckInterval time.Duration
+	primaryFunctions    map[string]struct{}
+	counter             atomic.Uint64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// hasReplicas returns true if read replicas are configured.
+func (p *PxDB) hasReplicas() bool {
+	return len(p.replicas.replicas) > 0
+}
+
+// startReplicas creates pools for replicas and starts health checks.
+// Unavailable replicas don't prevent the service from starting, they are excluded from balancing until they recover.
+func (p *PxDB) startReplicas(ctx context.Context) error {
+	if !p.hasReplicas() {
+		return nil
+	}
+
+	for i, r := range p.replicas.replicas {
+		if r.pool.Load() == nil {
+			var (
+				pool *pgxpool.Pool
+				err  error
+			)
+			if r.config != nil {
+				pool, err = pgxpool.NewWithConfig(ctx, r.config)
+			} else {
+				pool, err = pgxpool.New(ctx, r.dsn)
+			}
+			if err != nil {
+				p.stopReplicas()
+				return fmt.Errorf("failed to create pgx pool for replica %d of database %s: %w", i, p.name, err)
+			}
+
+			r.pool.Store(pool)
+			r.owned.Store(true)
+		}
+
+		p.checkReplica(ctx, i, r)
+	}
+
+	// ctx of Start is only valid during startup
+	ctxCheck, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	p.replicas.cancel = cancel
+
+	p.replicas.wg.Add(1)
+	go func() {
+		defer p.replicas.wg.Done()
+		p.healthCheckReplicas(ctxCheck)
+	}()
+
+	return nil
+}
+
+// stopReplicas stops health checks and closes replica pools created by PxDB.
+// Pools are closed after the health check goroutine exits, so it never pings a closed pool.
+func (p *PxDB) stopReplicas() {
+	if p.replicas.cancel != nil {
+		p.replicas.cancel()
+		p.replicas.wg.Wait()
+		p.replicas.cancel = nil
+	}
+
+	for _, r := range p.replicas.replicas {
+		// the replica is excluded from balancing before its pool is closed
+		r.healthy.Store(false)
+		r.checked.Store(false)
+
+		if r.owned.Swap(false) {
+			if pool := r.pool.Swap(nil); pool != nil {
+				pool.Close()
+			}
+		}
+	}
+}
+
+// healthCheckReplicas periodically pings replicas.
+func (p *PxDB) healthCheckReplicas(ctx context.Context) {
+	ticker := time.NewTicker(p.replicas.healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for i, r := range p.replicas.replicas {
+				p.checkReplica(ctx, i, r)
+			}
+		}
+	}
+}
+
+// checkReplica pings the replica and updates its state.
+func (p *PxDB) checkReplica(ctx context.Context, index int, r *replica) {
+	ctxPing, cancel := context.WithTimeout(ctx, p.replicas.healthCheckInterval)
+	defer cancel()
+
+	pool := r.pool.Load()
+	if pool == nil {
+		return
+	}
+
+	err := pool.Ping(ctxPing)
+	if ctx.Err() != nil {
+		return // stopping
+	}
+
+	healthy := err == nil
+	wasChecked := r.checked.Swap(true)
+
+	// log only state changes
+	if r.healthy.Swap(healthy) != healthy || (!wasChecked && !healthy) {
+		if healthy {
+			p.logger.Info(ctx, "replica is available", "database", p.name, "replica", index)
+		} else {
+			p.logger.Warn(ctx, "replica is unavailable", "database", p.name, "replica", index, "error", err)
+		}
+	}
+}
+
+// replicaPool returns the pool of a healthy replica chosen by the balancer or nil if there are no healthy replicas.
+func (p *PxDB) replicaPool() *pgxpool.Pool {
+	if !p.hasReplicas() {
+		return nil
+	}
+
+	switch p.replicas.balancer {
+	case ReplicaLeastConnections:
+		var (
+			best     *pgxpool.Pool
+			bestConn int32
+		)
+		for _, r := range p.replicas.replicas {
+			pool := r.pool.Load()
+			if pool == nil || !r.healthy.Load() {
+				continue
+			}
+			if acquired := pool.Stat().AcquiredConns(); best == nil || acquired < bestConn {
+				best, bestConn = pool, acquired
+			}
+		}
+		return best
+
+	case ReplicaRoundRobin:
+		fallthrough
+	default:
+		n := uint64(len(p.replicas.replicas))
+		start := p.replicas.counter.Add(1)
+		for i := range n {
+			r := p.replicas.replicas[(start+i)%n]
+			if pool := r.pool.Load(); pool != nil && r.healthy.Load() {
+				return pool
+			}
+		}
+		return nil
+	}
+}
+
+// readPool returns the pool for a query executed without transaction.
+// Reads go to a replica if possible, everything else goes to the primary.
+func (p *PxDB) readPool(primary bool, sql string) *pgxpool.Pool {
+	if primary || !isReadQuery(sql, p.replicas.primaryFunctions) {
+		return p.pool
+	}
+
+	if pool := p.replicaPool(); pool != nil {
+		return pool
+	}
+
+	return p.pool
+}
+
+// txPool returns the pool for a new transaction.
+// Read-only transactions go to a replica if possible. Transactions with locks always go to the primary.
+func (p *PxDB) txPool(opts txmgr.Options) *pgxpool.Pool {
+	if opts.Mode != txmgr.TxReadOnly || opts.Lock {
+		return p.pool
+	}
+
+	if pool := p.replicaPool(); pool != nil {
+		return pool
+	}
+
+	return p.pool
+}
+
+// isReadQuery returns true if sql is a SELECT without row locking, SELECT INTO and calls of functions
+// that write or can't be executed on a hot standby. functions are additional such functions in lower case.
+func isReadQuery(sql string, functions map[string]struct{}) bool {
+	sql = strings.TrimSpace(sql)
+	for strings.HasPrefix(sql, "(") {
+		sql = strings.TrimSpace(sql[1:])
+	}
+
+	const selectLen = len("SELECT")
+	if len(sql) < selectLen || !strings.EqualFold(sql[:selectLen], "SELECT") {
+		return false
+	}
+
+	upper := strings.ToUpper(sql)
+	for _, lock := range []string{"FOR UPDATE", "FOR NO KEY UPDATE", "FOR SHARE", "FOR KEY SHARE"} {
+		if strings.Contains(upper, lock) {
+			return false
+		}
+	}
+
+	if selectIntoRe.MatchString(sql) {
+		return false
+	}
+
+	for _, m := range functionCallRe.FindAllStringSubmatch(sql, -1) {
+		name := strings.ToLower(m[1])
+		if _, ok := functions[name]; ok || isPrimaryFunction(name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+var (
+	// function call, the name can be schema-qualified
+	functionCallRe = regexp.MustCompile(`(?:[A-Za-z_][A-Za-z0-9_$]*\.)?([A-Za-z_][A-Za-z0-9_$]*)\s*\(`)
+	// SELECT ... INTO creates a table
+	selectIntoRe = regexp.MustCompile(`(?i)\bINTO\b`)
+)
+
+// primaryFunctions built-in functions that write or can't be executed on a hot standby.
+//
+//nolint:gochecknoglobals // immutable
+var primaryFunctions = map[string]struct{}{
+	"nextval":            {},
+	"setval":             {},
+	"pg_notify":          {},
+	"txid_current":       {},
+	"pg_current_xact_id": {},
+	"lo_create":          {},
+	"lo_creat":           {},
+	"lo_import":          {},
+	"lo_from_bytea":      {},
+	"lo_put":             {},
+	"lo_unlink":          {},
+}
+
+// isPrimaryFunction returns true if the built-in function must be executed on the primary.
+// Advisory locks on a replica don't lock anything on the primary.
+func isPrimaryFunction(name string) bool {
+	if _, ok := primaryFunctions[name]; ok {
+		return true
+	}
+
+	return strings.HasPrefix(name, "pg_advisory_") || strings.HasPrefix(name, "pg_try_advisory_")
+}
@@ -0,0 +1,14 @@
+package db
+
+import (
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/n-r-w/pgh/v2/txmgr"
+)
+
+var _ txmgr.IRetryClassifier = (*PxDB)(nil)
+
+// IsRetryable returns true if the transaction failed with serialization failure or deadlock.
+// Implements txmgr.IRetryClassifier.
+func (p *PxDB) IsRetryable(err error) bool {
+	return px.IsRetryable(err)
+}
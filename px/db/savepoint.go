@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/n-r-w/pgh/v2/txmgr"
+)
+
+var _ txmgr.ITransactionSavepointer = (*PxDB)(nil)
+
+// BeginSavepoint runs a function within a savepoint of the transaction stored in ctx.
+// Implements txmgr.ITransactionSavepointer.
+func (p *PxDB) BeginSavepoint(ctx context.Context, f func(ctxTr context.Context) error, _ txmgr.Options) (err error) {
+	it, sp, err := p.beginSavepointHelper(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Create savepoint transaction object with the options of the outer transaction
+	spt := newTransaction(p, sp, it.opts)
+
+	// If panic occurs, rollback to the savepoint.
+	defer func() {
+		if rec := recover(); rec != nil {
+			_ = sp.Rollback(ctx)
+			spt.runRollbackHooks(ctx)
+			panic(rec) // Re-throw panic after rollback.
+		}
+	}()
+
+	defer func() {
+		errRollback := sp.Rollback(ctx)
+		if errRollback != nil && !errors.Is(errRollback, pgx.ErrTxClosed) {
+			if err != nil {
+				err = fmt.Errorf("%w (rollback to savepoint error: %v)", err, errRollback) //nolint:errorlint // ok for 2 errors
+			} else {
+				err = errRollback
+			}
+		}
+
+		if err != nil {
+			spt.runRollbackHooks(ctx)
+		}
+	}()
+
+	// Put savepoint transaction object in context
+	spCtx := spt.toContext(ctx)
+
+	if err = f(spCtx); err != nil {
+		return err
+	}
+
+	if err = sp.Commit(ctx); err != nil {
+		return err
+	}
+
+	// callbacks of the released savepoint are called when the outer transaction is finished
+	spt.moveHooks(it)
+
+	return nil
+}
+
+// BeginSavepointTx creates a savepoint in the transaction stored in ctx.
+// Implements txmgr.ITransactionSavepointer.
+func (p *PxDB) BeginSavepointTx(ctx context.Context, _ txmgr.Options,
+) (context.Context, txmgr.ITransactionFinisher, error) {
+	it, sp, err := p.beginSavepointHelper(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create savepoint transaction object with the options of the outer transaction and put it in context
+	spt := newTransaction(p, sp, it.opts)
+	spCtx := spt.toContext(ctx)
+
+	return spCtx, &savepointFinisher{
+		tx:     sp,
+		t:      spt,
+		parent: it,
+	}, nil
+}
+
+// beginSavepointHelper creates a savepoint in the transaction stored in ctx.
+func (p *PxDB) beginSavepointHelper(ctx context.Context) (*transaction, pgx.Tx, error) {
+	it, ok := txFromContext(ctx)
+	if !ok {
+		return nil, nil, errors.New("failed to create savepoint: transaction is not started")
+	}
+
+	if p != it.db {
+		panic("invalid DB") // this should never happen
+	}
+
+	// pgx implements nested transactions with SAVEPOINT
+	sp, err := it.tx.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	return it, sp, nil
+}
+
+// savepointFinisher implements txmgr.ITransactionFinisher for savepoints.
+type savepointFinisher struct {
+	tx     pgx.Tx
+	t      *transaction
+	parent *transaction
+}
+
+// Commit releases the savepoint.
+func (s *savepointFinisher) Commit(ctx context.Context) error {
+	if err := s.tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	// callbacks of the released savepoint are called when the outer transaction is finished
+	s.t.moveHooks(s.parent)
+
+	return nil
+}
+
+// Rollback rolls back to the savepoint.
+func (s *savepointFinisher) Rollback(ctx context.Context) error {
+	err := s.tx.Rollback(ctx)
+	if errors.Is(err, pgx.ErrTxClosed) {
+		// already finished
+		return err
+	}
+
+	s.t.runRollbackHooks(ctx)
+
+	return err
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/n-r-w/pgh/v2/txmgr"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPxDB_Savepoint(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, informer := testdock.GetPgxPool(t, testdock.DefaultPostgresDSN)
+
+	pxDB := New(
+		WithName("savepoint"),
+		WithDSN(informer.DSN()),
+	)
+
+	ctxStart, cancelStart := context.WithTimeout(ctx, 5*time.Second)
+	t.Cleanup(cancelStart)
+	require.NoError(t, pxDB.Start(ctxStart))
+	t.Cleanup(func() {
+		_ = pxDB.Stop(ctx)
+	})
+
+	_, err := pxDB.Connection(ctx).Exec(ctx, "CREATE TABLE test_savepoint (id int PRIMARY KEY, name text)")
+	require.NoError(t, err)
+
+	tm := txmgr.New(pxDB, pxDB)
+
+	require.NoError(t, tm.Begin(ctx, func(ctxTr context.Context) error {
+		_, errTr := pxDB.Connection(ctxTr).Exec(ctxTr, "INSERT INTO test_savepoint (id, name) VALUES (1, 'first')")
+		require.NoError(t, errTr)
+
+		// try to insert, on unique violation fall back to update
+		errTr = tm.Begin(ctxTr, func(ctxSp context.Context) error {
+			require.True(t, pxDB.InTransaction(ctxSp))
+			_, errSp := pxDB.Connection(ctxSp).Exec(ctxSp, "INSERT INTO test_savepoint (id, name) VALUES (1, 'second')")
+			return errSp
+		}, txmgr.WithSavepoint())
+		require.True(t, px.IsUniqueViolation(errTr))
+
+		// outer transaction is still usable
+		_, errTr = pxDB.Connection(ctxTr).Exec(ctxTr, "UPDATE test_savepoint SET name = 'second' WHERE id = 1")
+		require.NoError(t, errTr)
+
+		// released savepoint keeps its changes
+		ctxSp, finisher, errTr := tm.BeginTx(ctxTr, txmgr.WithSavepoint())
+		require.NoError(t, errTr)
+		_, errTr = pxDB.Connection(ctxSp).Exec(ctxSp, "INSERT INTO test_savepoint (id, name) VALUES (2, 'third')")
+		require.NoError(t, errTr)
+		require.NoError(t, finisher.Commit(ctxTr))
+
+		return nil
+	}))
+
+	var names []string
+	require.NoError(t, pgxscan.Select(ctx, pxDB.Connection(ctx), &names, "SELECT name FROM test_savepoint ORDER BY id"))
+	require.Equal(t, []string{"second", "third"}, names)
+}
@@ -122,6 +122,7 @@ type DB[T any] struct {
 	name                   string
 	runBucketFuncLimit     int
 	logger                 ctxlog.ILogger
+	interceptors           []conn.Interceptor
 
 	afterStartFunc func(context.Context, *DB[T]) error
 }
@@ -144,6 +145,7 @@ func New[T any](shardDB *shard.DB, buckets []*BucketInfo,
 		shardByBucketID:        make(map[BucketID]shard.ShardID),
 		logger:                 ctxlog.NewStubWrapper(),
 		runBucketFuncLimit:     defaultRunBucketFuncLimit,
+		interceptors:           nil,
 	}
 
 	for _, bucket := range buckets {
@@ -224,7 +226,7 @@ func (b *DB[T]) Connection(ctx context.Context, shardKey T, opt ...conn.Connecti
 func (b *DB[T]) ShardConnection(ctx context.Context, shardID shard.ShardID,
 	opt ...conn.ConnectionOption,
 ) conn.IConnection {
-	return b.shardDB.Connection(ctx, shardID.String(), opt...)
+	return conn.Intercept(b.shardDB.Connection(ctx, shardID.String(), opt...), b.interceptors...)
 }
 
 // NewBatch creates a new Batch based on the key.
@@ -296,6 +298,7 @@ func NewBucketClusterFromDSN(dsn []shard.DSNInfo, bucketInfo []*BucketInfo,
 		name:                   "",
 		runBucketFuncLimit:     0,
 		logger:                 nil,
+		interceptors:           nil,
 		afterStartFunc:         nil,
 	}
 	for _, o := range bucketOpts {
@@ -318,6 +321,8 @@ func (b *DB[T]) RunBucketFunc(ctx context.Context,
 
 	_ = b.shardDB.RunFunc(ctxGroup,
 		func(ctxFunc context.Context, shardID shard.ShardID, con conn.IConnection) error {
+			con = conn.Intercept(con, b.interceptors...)
+
 			for _, bucketInfo := range b.buckets {
 				if shardID != bucketInfo.ShardID {
 					continue
@@ -349,7 +354,9 @@ func (b *DB[T]) RunBucketFunc(ctx context.Context,
 func (b *DB[T]) RunShardFunc(ctx context.Context, f func(ctx context.Context,
 	shardID shard.ShardID, con conn.IConnection) error,
 ) error {
-	return b.shardDB.RunFunc(ctx, f, len(b.infoByShard))
+	return b.shardDB.RunFunc(ctx, func(ctx context.Context, shardID shard.ShardID, con conn.IConnection) error {
+		return f(ctx, shardID, conn.Intercept(con, b.interceptors...))
+	}, len(b.infoByShard))
 }
 
 // GroupByShard groups objects by cluster shards based on a function that returns a key for each object.
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
 )
 
 // Option option for bucket.DB.
@@ -36,3 +37,11 @@ func WithLogger[T any](logger ctxlog.ILogger) Option[T] {
 		b.logger = logger
 	}
 }
+
+// WithInterceptors adds interceptors called for each query executed through connections of bucket.DB.
+// They are called in addition to interceptors of shard.DB and see queries with the bucket schema already set.
+func WithInterceptors[T any](interceptors ...conn.Interceptor) Option[T] {
+	return func(b *DB[T]) {
+		b.interceptors = append(b.interceptors, interceptors...)
+	}
+}
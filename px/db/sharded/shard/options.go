@@ -4,6 +4,7 @@ package shard
 import (
 	"github.com/cenkalti/backoff/v5"
 	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
 )
 
 // Option option for DB.
@@ -29,3 +30,11 @@ func WithRestartPolicy(restartPolicy []backoff.RetryOption) Option {
 		s.restartPolicy = restartPolicy
 	}
 }
+
+// WithInterceptors adds interceptors called for each query executed through connections of all shards.
+// They are called in addition to interceptors of the shard connectors.
+func WithInterceptors(interceptors ...conn.Interceptor) Option {
+	return func(s *DB) {
+		s.interceptors = append(s.interceptors, interceptors...)
+	}
+}
@@ -90,6 +90,7 @@ type DB struct {
 	name          string
 	logger        ctxlog.ILogger
 	restartPolicy []backoff.RetryOption
+	interceptors  []conn.Interceptor
 }
 
 var _ bootstrap.IService = (*DB)(nil)
@@ -102,6 +103,7 @@ func New(shardInfo []*ShardInfo, shardFunc ShardFunc, opts ...Option) *DB {
 		name:          "",
 		logger:        ctxlog.NewStubWrapper(),
 		restartPolicy: nil,
+		interceptors:  nil,
 	}
 
 	for _, opt := range opts {
@@ -130,6 +132,7 @@ func NewFromDSN(dsn []DSNInfo, shardFunc ShardFunc, opts ...Option) *DB {
 		name:          "",
 		logger:        ctxlog.NewStubWrapper(),
 		restartPolicy: nil,
+		interceptors:  nil,
 	}
 
 	for _, opt := range opts {
@@ -251,7 +254,7 @@ func (s *DB) Connection(ctx context.Context, shardKey string, opt ...conn.Connec
 		return conn.NewDatabaseErrorWrapper(fmt.Errorf("shard %s not found", shardKey))
 	}
 
-	return info.Connector.Connection(ctx, opt...)
+	return conn.Intercept(info.Connector.Connection(ctx, opt...), s.interceptors...)
 }
 
 // Begin starts a function in a transaction for the specified shardKey.
@@ -295,7 +298,7 @@ func (s *DB) RunFunc(ctx context.Context,
 	for _, info := range s.shardInfo {
 		if eg != nil {
 			eg.Go(func() error {
-				con := info.Connector.Connection(ctx)
+				con := conn.Intercept(info.Connector.Connection(ctx), s.interceptors...)
 				if err := f(ctx, info.ShardID, con); err != nil {
 					return fmt.Errorf("failed to run function for shard %d: %w", info.ShardID, err)
 				}
@@ -303,7 +306,7 @@ func (s *DB) RunFunc(ctx context.Context,
 				return nil
 			})
 		} else {
-			con := info.Connector.Connection(ctx)
+			con := conn.Intercept(info.Connector.Connection(ctx), s.interceptors...)
 			if err := f(ctx, info.ShardID, con); err != nil {
 				return fmt.Errorf("failed to run function for shard %d: %w", info.ShardID, err)
 			}
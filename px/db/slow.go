@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// slowQueryExplainTimeout limits the time of EXPLAIN for a slow query.
+const slowQueryExplainTimeout = 5 * time.Second
+
+// slowQueryInterceptor logs queries executed longer than the threshold of PxDB.
+type slowQueryInterceptor struct {
+	db *PxDB
+	// lastExplain time of the last EXPLAIN in unix nanoseconds
+	lastExplain atomic.Int64
+}
+
+func newSlowQueryInterceptor(db *PxDB) *slowQueryInterceptor {
+	return &slowQueryInterceptor{db: db, lastExplain: atomic.Int64{}}
+}
+
+// Before does nothing.
+func (s *slowQueryInterceptor) Before(ctx context.Context, _ *conn.QueryInfo) (context.Context, error) {
+	return ctx, nil
+}
+
+// After logs the query if it is slow. If EXPLAIN is enabled, the plan is obtained in the background
+// and the query is logged after that.
+func (s *slowQueryInterceptor) After(ctx context.Context, q *conn.QueryInfo) {
+	if q.Duration < s.db.slowQueryThreshold {
+		return
+	}
+
+	attrs := []any{
+		"database", s.db.name,
+		"command", q.Command,
+		"latency", q.Duration,
+		"threshold", s.db.slowQueryThreshold,
+		"args", s.db.argPolicy.Args(q.SQL, q.Args),
+	}
+
+	if query := logQueryText(q, s.db.argPolicy); query != "" {
+		attrs = append(attrs, "query", query)
+	}
+
+	spanContext := trace.SpanFromContext(ctx).SpanContext()
+	if spanContext.TraceID().IsValid() {
+		attrs = append(attrs, "trace_id", spanContext.TraceID().String())
+	}
+
+	if q.Err != nil {
+		attrs = append(attrs, "error", q.Err)
+	}
+
+	if !s.allowExplain(q) {
+		s.db.logger.Warn(ctx, "slow dbquery", attrs...)
+		return
+	}
+
+	// the goroutine outlives the query, so its context must not be canceled with the query
+	sql, args := q.SQL, q.Args
+	// the plan of a query with redacted arguments would contain their values
+	generic := s.db.argPolicy.redacts(sql, args)
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		plan, err := s.explain(ctx, sql, args, generic)
+		if err != nil {
+			attrs = append(attrs, "explain_error", err)
+		} else {
+			attrs = append(attrs, "plan", plan)
+		}
+
+		s.db.logger.Warn(ctx, "slow dbquery", attrs...)
+	}()
+}
+
+// allowExplain checks if EXPLAIN is enabled, can be applied to the query and is not rate-limited.
+func (s *slowQueryInterceptor) allowExplain(q *conn.QueryInfo) bool {
+	if s.db.slowQueryExplainInterval <= 0 || !explainable(q) {
+		return false
+	}
+
+	now := time.Now().UnixNano()
+	last := s.lastExplain.Load()
+	if last != 0 && now-last < s.db.slowQueryExplainInterval.Nanoseconds() {
+		return false
+	}
+
+	return s.lastExplain.CompareAndSwap(last, now)
+}
+
+// explain returns the plan of the query in JSON format. EXPLAIN without ANALYZE doesn't execute the query.
+// It runs on a separate connection of the primary pool, so the plan of a query that depends
+// on uncommitted changes of its transaction (e.g. temporary tables) can't be obtained.
+// If generic is true, the generic plan is obtained without arguments, so their values are not inlined into it.
+func (s *slowQueryInterceptor) explain(ctx context.Context, sql string, args []any, generic bool) (string, error) {
+	if s.db.pool == nil {
+		return "", errors.New("database is not started")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, slowQueryExplainTimeout)
+	defer cancel()
+
+	if generic {
+		return s.explainGeneric(ctx, sql)
+	}
+
+	var plan string
+	if err := s.db.pool.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+sql, args...).Scan(&plan); err != nil {
+		return "", fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	return plan, nil
+}
+
+// explainGeneric returns the generic plan of the query in JSON format. GENERIC_PLAN requires PostgreSQL 16+.
+// The query is sent with the simple protocol, because its parameters have no values.
+func (s *slowQueryInterceptor) explainGeneric(ctx context.Context, sql string) (string, error) {
+	c, err := s.db.pool.Acquire(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to explain query: %w", err)
+	}
+	defer c.Release()
+
+	results, err := c.Conn().PgConn().Exec(ctx, "EXPLAIN (GENERIC_PLAN, FORMAT JSON) "+sql).ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	if len(results) != 1 || len(results[0].Rows) != 1 || len(results[0].Rows[0]) == 0 {
+		return "", errors.New("failed to explain query: unexpected result")
+	}
+
+	return string(results[0].Rows[0][0]), nil
+}
+
+// explainable checks if EXPLAIN can be applied to the query.
+func explainable(q *conn.QueryInfo) bool {
+	switch q.Command {
+	case conn.CommandExec, conn.CommandQuery, conn.CommandQueryRow:
+	default:
+		return false
+	}
+
+	fields := strings.Fields(q.SQL)
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "MERGE", "WITH", "VALUES", "TABLE":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestExplainable(t *testing.T) {
+	t.Parallel()
+
+	for sql, want := range map[string]bool{
+		"SELECT 1":                             true,
+		"\n  select * from users":              true,
+		"WITH x AS (SELECT 1) SELECT * FROM x": true,
+		"UPDATE users SET name = $1":           true,
+		"CREATE TABLE users (id int)":          false,
+		"VACUUM users":                         false,
+		"":                                     false,
+	} {
+		//nolint:exhaustruct // test
+		require.Equal(t, want, explainable(&conn.QueryInfo{Command: conn.CommandQuery, SQL: sql}), sql)
+	}
+
+	//nolint:exhaustruct // test
+	require.False(t, explainable(&conn.QueryInfo{Command: conn.CommandSendBatch, SQL: "SELECT 1"}))
+}
+
+func TestSlowQueryInterceptor(t *testing.T) {
+	t.Parallel()
+
+	logger := ctxlog.NewMockILogger(gomock.NewController(t))
+	p := New(WithLogger(logger), WithSlowQueryLog(time.Second), WithSlowQueryExplain(time.Hour))
+	require.NotNil(t, p.slowQuery)
+
+	// fast query is not logged
+	//nolint:exhaustruct // test
+	p.slowQuery.After(context.Background(), &conn.QueryInfo{
+		Command:  conn.CommandQuery,
+		SQL:      "SELECT 1",
+		Duration: time.Millisecond,
+	})
+
+	// slow query without explain: not explainable statement
+	logger.EXPECT().Warn(gomock.Any(), "slow dbquery", gomock.Any()).
+		Do(func(_ context.Context, _ string, args ...any) {
+			require.Contains(t, args, "VACUUM users")
+			require.Contains(t, args, 2*time.Second)
+		})
+	//nolint:exhaustruct // test
+	p.slowQuery.After(context.Background(), &conn.QueryInfo{
+		Command:  conn.CommandExec,
+		SQL:      "VACUUM users",
+		Duration: 2 * time.Second,
+	})
+
+	// explain is rate-limited
+	//nolint:exhaustruct // test
+	q := &conn.QueryInfo{Command: conn.CommandQuery, SQL: "SELECT 1"}
+	require.True(t, p.slowQuery.allowExplain(q))
+	require.False(t, p.slowQuery.allowExplain(q))
+
+	require.Nil(t, New().slowQuery)
+}
+
+func TestPxDB_SlowQueryLog(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, informer := testdock.GetPgxPool(t, testdock.DefaultPostgresDSN)
+
+	logger := ctxlog.NewMockILogger(gomock.NewController(t))
+	logger.EXPECT().Debug(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	logged := make(chan []any, 1)
+	logger.EXPECT().Warn(gomock.Any(), "slow dbquery", gomock.Any()).
+		Do(func(_ context.Context, _ string, args ...any) {
+			logged <- args
+		})
+
+	pgdbImpl := New(
+		WithDSN(informer.DSN()),
+		WithLogger(logger),
+		WithSlowQueryLog(50*time.Millisecond),
+		WithSlowQueryExplain(time.Minute),
+	)
+
+	require.NoError(t, pgdbImpl.Start(ctx))
+	t.Cleanup(func() { _ = pgdbImpl.Stop(ctx) })
+
+	var n int
+	require.NoError(t, pgdbImpl.Connection(ctx).QueryRow(ctx, "SELECT $1::int FROM pg_sleep(0.1)", 1).Scan(&n))
+
+	select {
+	case args := <-logged:
+		require.Contains(t, args, "plan")
+		require.NotContains(t, args, "explain_error")
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "slow query is not logged")
+	}
+}
+
+func TestPxDB_SlowQueryLogRedacted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, informer := testdock.GetPgxPool(t, testdock.DefaultPostgresDSN)
+
+	logger := ctxlog.NewMockILogger(gomock.NewController(t))
+	logger.EXPECT().Debug(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	logged := make(chan []any, 1)
+	logger.EXPECT().Warn(gomock.Any(), "slow dbquery", gomock.Any()).
+		Do(func(_ context.Context, _ string, args ...any) {
+			logged <- args
+		})
+
+	pgdbImpl := New(
+		WithDSN(informer.DSN()),
+		WithLogger(logger),
+		WithSlowQueryLog(50*time.Millisecond),
+		WithSlowQueryExplain(time.Minute),
+		WithArgPolicy(NewArgPolicy(WithRedactedColumns("password"))),
+	)
+
+	require.NoError(t, pgdbImpl.Start(ctx))
+	t.Cleanup(func() { _ = pgdbImpl.Stop(ctx) })
+
+	const secret = "very-secret-value"
+
+	var n int
+	require.NoError(t, pgdbImpl.Connection(ctx).QueryRow(ctx,
+		"SELECT count(*) FROM (SELECT 'x'::text AS password) u, pg_sleep(0.1) WHERE password = $1", secret).Scan(&n))
+
+	select {
+	case args := <-logged:
+		// the plan is generic or not obtained, the value is never inlined
+		require.NotContains(t, fmt.Sprint(args...), secret)
+		require.Contains(t, fmt.Sprint(args...), RedactedArg)
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "slow query is not logged")
+	}
+}
@@ -0,0 +1,238 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/pgh/v2/internal/pgerr"
+	"github.com/n-r-w/pgh/v2/px/db"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+	"github.com/n-r-w/pgh/v2/txmgr"
+)
+
+// Span attributes following OpenTelemetry semantic conventions for database clients.
+const (
+	AttrDBSystem             = "db.system"
+	AttrDBName               = "db.name"
+	AttrDBStatement          = "db.statement"
+	AttrDBOperation          = "db.operation"
+	AttrDBRowsAffected       = "db.rows_affected"
+	AttrDBStatusCode         = "db.response.status_code"
+	AttrDBBatchIndex         = "db.batch.index"
+	AttrDBBatchSize          = "db.batch.size"
+	AttrDBTransaction        = "db.transaction"
+	AttrDBTransactionLevel   = "db.transaction.isolation_level"
+	AttrDBTransactionMode    = "db.transaction.access_mode"
+	AttrDBTransactionOutcome = "db.transaction.outcome"
+	AttrError                = "error"
+)
+
+const (
+	dbSystemPostgreSQL        = "postgresql"
+	batchQueryEvent           = "batch query"
+	transactionOutcomeFailed  = "failed"
+	transactionOutcomeSuccess = "ok"
+)
+
+// NewInterceptor creates an interceptor that sends telemetry for each query: a span with the query attributes,
+// the request duration, count and errors. It is used by Service and can be passed to PxDB or the sharded DBs
+// with their WithInterceptors options.
+// The span of Query ends when rows are closed, of QueryRow after Scan and of SendBatch when the results are closed.
+// Transaction spans (begin, commit, rollback) are created only if the interceptor is passed to db.WithInterceptors,
+// because transactions are managed by PxDB.
+func NewInterceptor(telemetry ITelemetry, opts ...Option) conn.Interceptor {
+	i := &interceptor{
+		telemetry: telemetry,
+		argPolicy: db.NewArgPolicy(),
+		dbName:    "",
+	}
+
+	for _, o := range opts {
+		o(i)
+	}
+
+	return i
+}
+
+// interceptor sends telemetry for queries.
+type interceptor struct {
+	telemetry ITelemetry
+	argPolicy *db.ArgPolicy
+	dbName    string
+}
+
+// spanKey context key of the query span.
+type spanKey struct{}
+
+// requestKey context key of RequestInfo.
+type requestKey struct{}
+
+// RequestInfo information about the current request. The interceptor puts it into the context
+// passed to ITelemetry, so implementations can label metrics with it.
+type RequestInfo struct {
+	// Command one of conn.Command* constants.
+	Command string
+	// Database name set by WithDatabaseName.
+	Database string
+}
+
+// RequestFromContext returns information about the current request put into the context by the interceptor.
+func RequestFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestKey{}).(RequestInfo)
+	return info, ok
+}
+
+// Before starts the query span.
+func (i *interceptor) Before(ctx context.Context, q *conn.QueryInfo) (context.Context, error) {
+	ctx = context.WithValue(ctx, requestKey{}, RequestInfo{Command: q.Command, Database: i.dbName})
+
+	ctxSpan, span := i.telemetry.StartSpan(ctx, spanName(q))
+	if span == nil {
+		return ctx, nil
+	}
+
+	span.AddAttributes(i.queryAttributes(q))
+
+	if eventer, ok := span.(ISpanEventer); ok && q.Batch != nil {
+		for idx, bq := range q.Batch.QueuedQueries {
+			eventer.AddEvent(batchQueryEvent, []Attribute{
+				{AttrDBBatchIndex, idx},
+				{AttrDBStatement, bq.SQL},
+				{"query.arg.", i.argPolicy.Args(bq.SQL, bq.Arguments)},
+			})
+		}
+	}
+
+	return context.WithValue(ctxSpan, spanKey{}, span), nil
+}
+
+// After records the request metrics, adds the results to the query span and ends it.
+func (i *interceptor) After(ctx context.Context, q *conn.QueryInfo) {
+	i.telemetry.ObserveRequestDuration(ctx, q.Duration)
+
+	i.telemetry.ObserveRequest(ctx)
+	if q.Err != nil {
+		i.telemetry.ObserveRequestError(ctx, q.Err)
+	}
+
+	span, ok := ctx.Value(spanKey{}).(ISpan)
+	if !ok {
+		return
+	}
+
+	span.AddAttributes(resultAttributes(q))
+	span.End()
+}
+
+// queryAttributes returns attributes of the query span known before the query.
+func (i *interceptor) queryAttributes(q *conn.QueryInfo) []Attribute {
+	attributes := make([]Attribute, 0, 10) //nolint:mnd // maximum number of attributes
+	attributes = append(attributes,
+		Attribute{AttrDBSystem, dbSystemPostgreSQL},
+		Attribute{AttrDBOperation, operation(q)},
+		Attribute{"command", q.Command},
+		Attribute{"query.arg.", i.argPolicy.Args(q.SQL, q.Args)},
+		Attribute{AttrDBTransaction, q.InTransaction})
+	if i.dbName != "" {
+		attributes = append(attributes, Attribute{AttrDBName, i.dbName})
+	}
+	if q.SQL != "" {
+		attributes = append(attributes, Attribute{AttrDBStatement, q.SQL})
+	}
+	if details := queryDetails(q); details != "" {
+		attributes = append(attributes, Attribute{"details", details})
+	}
+	if q.Batch != nil {
+		attributes = append(attributes, Attribute{AttrDBBatchSize, q.Batch.Len()})
+	}
+	if isTransactionCommand(q.Command) || q.InTransaction {
+		attributes = append(attributes,
+			Attribute{AttrDBTransactionLevel, isolationLevel(q.TransactionOptions.Level)},
+			Attribute{AttrDBTransactionMode, accessMode(q.TransactionOptions.Mode)})
+	}
+
+	return attributes
+}
+
+// resultAttributes returns attributes of the query span known after the query.
+func resultAttributes(q *conn.QueryInfo) []Attribute {
+	attributes := []Attribute{{AttrDBRowsAffected, q.RowsAffected}}
+
+	if isTransactionCommand(q.Command) {
+		outcome := transactionOutcomeSuccess
+		if q.Err != nil {
+			outcome = transactionOutcomeFailed
+		}
+		attributes = append(attributes, Attribute{AttrDBTransactionOutcome, outcome})
+	}
+
+	if q.Err != nil {
+		attributes = append(attributes, Attribute{AttrError, q.Err.Error()})
+		if code := pgerr.Code(q.Err); code != "" {
+			attributes = append(attributes, Attribute{AttrDBStatusCode, code})
+		}
+	}
+
+	return attributes
+}
+
+// spanName returns the name of the query span.
+func spanName(q *conn.QueryInfo) string {
+	if isTransactionCommand(q.Command) {
+		return "pgdb " + q.Command
+	}
+
+	return "pgdb"
+}
+
+// operation returns the name of the operation: the first keyword of SQL or the command.
+func operation(q *conn.QueryInfo) string {
+	if fields := strings.Fields(q.SQL); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+
+	return strings.ToUpper(q.Command)
+}
+
+// queryDetails returns the details attribute of the query span.
+func queryDetails(q *conn.QueryInfo) string {
+	switch q.Command {
+	case conn.CommandCopyFrom:
+		return fmt.Sprintf("table: %s; columns: %s", q.Table, strings.Join(q.Columns, ", "))
+	case conn.CommandSendBatch:
+		return "batch"
+	default:
+		return ""
+	}
+}
+
+// isTransactionCommand checks if the command is a transaction lifecycle command.
+func isTransactionCommand(command string) bool {
+	return command == conn.CommandBegin || command == conn.CommandCommit || command == conn.CommandRollback
+}
+
+// isolationLevel returns the name of the isolation level.
+func isolationLevel(level txmgr.TransactionLevel) string {
+	switch level {
+	case txmgr.TxReadUncommitted:
+		return "read uncommitted"
+	case txmgr.TxRepeatableRead:
+		return "repeatable read"
+	case txmgr.TxSerializable:
+		return "serializable"
+	case txmgr.TxLevelDefault, txmgr.TxReadCommitted:
+		return "read committed"
+	default:
+		return "unknown"
+	}
+}
+
+// accessMode returns the name of the access mode.
+func accessMode(mode txmgr.TransactionMode) string {
+	if mode == txmgr.TxReadOnly {
+		return "read only"
+	}
+
+	return "read write"
+}
@@ -0,0 +1,195 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/n-r-w/pgh/v2/px/db"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+	"github.com/n-r-w/pgh/v2/txmgr"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type testSpan struct {
+	name       string
+	attributes map[string]any
+	events     [][]Attribute
+	ended      bool
+}
+
+func (s *testSpan) AddAttributes(attributes []Attribute) {
+	for _, a := range attributes {
+		s.attributes[a.Key] = a.Value
+	}
+}
+
+func (s *testSpan) AddEvent(_ string, attributes []Attribute) {
+	s.events = append(s.events, attributes)
+}
+
+func (s *testSpan) End() {
+	s.ended = true
+}
+
+type testTelemetry struct {
+	spans    []*testSpan
+	requests int
+	errors   []error
+}
+
+func (t *testTelemetry) StartSpan(ctx context.Context, name string) (context.Context, ISpan) {
+	span := &testSpan{name: name, attributes: make(map[string]any), events: nil, ended: false}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (t *testTelemetry) ObserveRequestDuration(context.Context, time.Duration) {}
+
+func (t *testTelemetry) ObserveRequest(context.Context) {
+	t.requests++
+}
+
+func (t *testTelemetry) ObserveRequestError(_ context.Context, err error) {
+	t.errors = append(t.errors, err)
+}
+
+func TestInterceptor_Query(t *testing.T) {
+	t.Parallel()
+
+	tel := &testTelemetry{spans: nil, requests: 0, errors: nil}
+	i := NewInterceptor(tel,
+		WithDatabaseName("users"),
+		WithArgPolicy(db.NewArgPolicy(db.WithRedactedColumns("password"))))
+
+	//nolint:exhaustruct // test
+	q := &conn.QueryInfo{
+		Command:            conn.CommandQuery,
+		SQL:                "select id from users where password = $1",
+		Args:               []any{"secret"},
+		InTransaction:      true,
+		TransactionOptions: txmgr.Options{Level: txmgr.TxSerializable, Mode: txmgr.TxReadOnly},
+	}
+
+	ctx, err := i.Before(context.Background(), q)
+	require.NoError(t, err)
+	require.Len(t, tel.spans, 1)
+
+	span := tel.spans[0]
+	require.Equal(t, "pgdb", span.name)
+	require.Equal(t, "postgresql", span.attributes[AttrDBSystem])
+	require.Equal(t, "users", span.attributes[AttrDBName])
+	require.Equal(t, "SELECT", span.attributes[AttrDBOperation])
+	require.Equal(t, q.SQL, span.attributes[AttrDBStatement])
+	require.Equal(t, []any{db.RedactedArg}, span.attributes["query.arg."])
+	require.Equal(t, "serializable", span.attributes[AttrDBTransactionLevel])
+	require.Equal(t, "read only", span.attributes[AttrDBTransactionMode])
+	require.False(t, span.ended)
+
+	q.RowsAffected = 3
+	q.Err = &pgconn.PgError{Code: pgerrcode.SerializationFailure} //nolint:exhaustruct // test
+	i.After(ctx, q)
+
+	require.True(t, span.ended)
+	require.Equal(t, int64(3), span.attributes[AttrDBRowsAffected])
+	require.Equal(t, pgerrcode.SerializationFailure, span.attributes[AttrDBStatusCode])
+	require.Equal(t, 1, tel.requests)
+	require.Len(t, tel.errors, 1)
+}
+
+func TestInterceptor_QueryRowNoRows(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	con := conn.NewMockIConnection(ctrl)
+	row := px.NewMockRow(ctrl)
+
+	con.EXPECT().InTransaction().Return(false)
+	con.EXPECT().QueryRow(gomock.Any(), "SELECT name FROM users WHERE id = $1", 1).Return(row)
+	row.EXPECT().Scan(gomock.Any()).Return(pgx.ErrNoRows)
+
+	tel := &testTelemetry{spans: nil, requests: 0, errors: nil}
+	ic := conn.Intercept(con, NewInterceptor(tel))
+
+	// a query without rows is not a failed request
+	var name string
+	require.ErrorIs(t, ic.QueryRow(context.Background(), "SELECT name FROM users WHERE id = $1", 1).Scan(&name),
+		pgx.ErrNoRows)
+	require.Equal(t, 1, tel.requests)
+	require.Empty(t, tel.errors)
+	require.NotContains(t, tel.spans[0].attributes, AttrError)
+	require.Equal(t, int64(0), tel.spans[0].attributes[AttrDBRowsAffected])
+}
+
+func TestNewConnectionWrapper(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	con := conn.NewMockIConnection(ctrl)
+
+	con.EXPECT().InTransaction().Return(false).Times(2)
+	con.EXPECT().Exec(gomock.Any(), "DELETE FROM users").Return(pgconn.NewCommandTag("DELETE 2"), nil)
+
+	tel := &testTelemetry{spans: nil, requests: 0, errors: nil}
+	w := NewConnectionWrapper(con, tel, WithDatabaseName("users")) //nolint:staticcheck // deprecated API is tested
+
+	require.False(t, w.InTransaction())
+	_, err := w.Exec(context.Background(), "DELETE FROM users")
+	require.NoError(t, err)
+	require.Equal(t, 1, tel.requests)
+	require.Equal(t, int64(2), tel.spans[0].attributes[AttrDBRowsAffected])
+	require.Equal(t, "users", tel.spans[0].attributes[AttrDBName])
+}
+
+func TestInterceptor_Batch(t *testing.T) {
+	t.Parallel()
+
+	tel := &testTelemetry{spans: nil, requests: 0, errors: nil}
+	i := NewInterceptor(tel)
+
+	batch := &pgx.Batch{} //nolint:exhaustruct // test
+	batch.Queue("INSERT INTO users (name) VALUES ($1)", "alice")
+	batch.Queue("DELETE FROM users")
+
+	//nolint:exhaustruct // test
+	q := &conn.QueryInfo{Command: conn.CommandSendBatch, Batch: batch}
+
+	ctx, err := i.Before(context.Background(), q)
+	require.NoError(t, err)
+	i.After(ctx, q)
+
+	span := tel.spans[0]
+	require.Equal(t, 2, span.attributes[AttrDBBatchSize])
+	require.Equal(t, "SEND BATCH", span.attributes[AttrDBOperation])
+	require.Len(t, span.events, 2)
+	require.Contains(t, span.events[0], Attribute{AttrDBStatement, "INSERT INTO users (name) VALUES ($1)"})
+	require.Contains(t, span.events[1], Attribute{AttrDBBatchIndex, 1})
+}
+
+func TestInterceptor_Transaction(t *testing.T) {
+	t.Parallel()
+
+	tel := &testTelemetry{spans: nil, requests: 0, errors: nil}
+	i := NewInterceptor(tel)
+
+	errCommit := errors.New("commit failed")
+
+	//nolint:exhaustruct // test
+	q := &conn.QueryInfo{Command: conn.CommandCommit, SQL: "COMMIT", InTransaction: true}
+	require.ErrorIs(t, conn.Run(context.Background(), []conn.Interceptor{i}, q, func(context.Context) (int64, error) {
+		return 0, errCommit
+	}), errCommit)
+
+	span := tel.spans[0]
+	require.Equal(t, "pgdb commit", span.name)
+	require.Equal(t, "read committed", span.attributes[AttrDBTransactionLevel])
+	require.Equal(t, "failed", span.attributes[AttrDBTransactionOutcome])
+	require.Equal(t, errCommit.Error(), span.attributes[AttrError])
+	require.True(t, span.ended)
+}
@@ -0,0 +1,22 @@
+package telemetry
+
+import "github.com/n-r-w/pgh/v2/px/db"
+
+// Option option for the telemetry interceptor.
+type Option func(*interceptor)
+
+// WithArgPolicy sets how query arguments are written to the "query.arg." span attribute.
+// Use the same policy as for db.WithArgPolicy to keep logs and traces consistent.
+// Default is db.NewArgPolicy() that only truncates values.
+func WithArgPolicy(policy *db.ArgPolicy) Option {
+	return func(i *interceptor) {
+		i.argPolicy = policy
+	}
+}
+
+// WithDatabaseName sets the database name written to the "db.name" span attribute.
+func WithDatabaseName(name string) Option {
+	return func(i *interceptor) {
+		i.dbName = name
+	}
+}
@@ -0,0 +1,212 @@
+// Package otel implements telemetry.ITelemetry with OpenTelemetry tracing and metrics.
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/n-r-w/pgh/v2/internal/pgerr"
+	"github.com/n-r-w/pgh/v2/px/db/telemetry"
+	gootel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName name of the tracer and the meter.
+const instrumentationName = "github.com/n-r-w/pgh/v2/px/db/telemetry/otel"
+
+// Metric names.
+const (
+	MetricDuration = "db.client.operation.duration"
+	MetricRequests = "db.client.requests"
+	MetricErrors   = "db.client.errors"
+)
+
+// Metric labels.
+const (
+	LabelOperation     = "db.operation"
+	LabelDatabase      = "db.name"
+	LabelSQLStateClass = "db.sqlstate.class"
+)
+
+// unknownSQLStateClass is used as the SQLSTATE class of errors not returned by the server.
+const unknownSQLStateClass = "none"
+
+// Telemetry implements telemetry.ITelemetry with OpenTelemetry.
+// Spans are created with the tracer of TracerProvider, the request duration is recorded to a histogram,
+// requests and errors are counted. Metrics are labelled by the command and the database name
+// from telemetry.RequestFromContext, errors also by the SQLSTATE class.
+type Telemetry struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+}
+
+var _ telemetry.ITelemetry = (*Telemetry)(nil)
+
+// Option option for Telemetry.
+type Option func(*options)
+
+type options struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider sets TracerProvider. Default is the global TracerProvider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets MeterProvider. Default is the global MeterProvider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(o *options) {
+		o.meterProvider = provider
+	}
+}
+
+// New creates Telemetry. Returns an error if metric instruments can't be created.
+func New(opts ...Option) (*Telemetry, error) {
+	o := &options{
+		tracerProvider: gootel.GetTracerProvider(),
+		meterProvider:  gootel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	meter := o.meterProvider.Meter(instrumentationName)
+
+	duration, err := meter.Float64Histogram(MetricDuration,
+		metric.WithDescription("Duration of database requests"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create duration histogram: %w", err)
+	}
+
+	requests, err := meter.Int64Counter(MetricRequests,
+		metric.WithDescription("Number of database requests"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create requests counter: %w", err)
+	}
+
+	errors, err := meter.Int64Counter(MetricErrors,
+		metric.WithDescription("Number of failed database requests"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create errors counter: %w", err)
+	}
+
+	return &Telemetry{
+		tracer:   o.tracerProvider.Tracer(instrumentationName),
+		duration: duration,
+		requests: requests,
+		errors:   errors,
+	}, nil
+}
+
+// StartSpan starts a client span.
+func (t *Telemetry) StartSpan(ctx context.Context, name string) (context.Context, telemetry.ISpan) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return ctx, &Span{span: span}
+}
+
+// ObserveRequestDuration records the request duration in seconds.
+func (t *Telemetry) ObserveRequestDuration(ctx context.Context, duration time.Duration) {
+	t.duration.Record(ctx, duration.Seconds(), metric.WithAttributes(requestLabels(ctx)...))
+}
+
+// ObserveRequest counts the request.
+func (t *Telemetry) ObserveRequest(ctx context.Context) {
+	t.requests.Add(ctx, 1, metric.WithAttributes(requestLabels(ctx)...))
+}
+
+// ObserveRequestError counts the failed request.
+func (t *Telemetry) ObserveRequestError(ctx context.Context, err error) {
+	class := pgerr.Class(err)
+	if class == "" {
+		class = unknownSQLStateClass
+	}
+
+	t.errors.Add(ctx, 1, metric.WithAttributes(append(requestLabels(ctx), attribute.String(LabelSQLStateClass, class))...))
+}
+
+// requestLabels returns metric labels of the request.
+func requestLabels(ctx context.Context) []attribute.KeyValue {
+	info, _ := telemetry.RequestFromContext(ctx)
+
+	return []attribute.KeyValue{
+		attribute.String(LabelOperation, info.Command),
+		attribute.String(LabelDatabase, info.Database),
+	}
+}
+
+// Span implements telemetry.ISpan and telemetry.ISpanEventer with an OpenTelemetry span.
+type Span struct {
+	span trace.Span
+}
+
+var (
+	_ telemetry.ISpan        = (*Span)(nil)
+	_ telemetry.ISpanEventer = (*Span)(nil)
+)
+
+// AddAttributes sets attributes of the span. The telemetry.AttrError attribute also sets the error status.
+func (s *Span) AddAttributes(attributes []telemetry.Attribute) {
+	for _, a := range attributes {
+		if a.Key == telemetry.AttrError {
+			s.span.SetStatus(codes.Error, fmt.Sprint(a.Value))
+		}
+	}
+
+	s.span.SetAttributes(convertAttributes(attributes)...)
+}
+
+// AddEvent adds an event to the span.
+func (s *Span) AddEvent(name string, attributes []telemetry.Attribute) {
+	s.span.AddEvent(name, trace.WithAttributes(convertAttributes(attributes)...))
+}
+
+// End ends the span.
+func (s *Span) End() {
+	s.span.End()
+}
+
+// convertAttributes converts telemetry attributes to OpenTelemetry attributes.
+// Values of unsupported types are converted to strings.
+func convertAttributes(attributes []telemetry.Attribute) []attribute.KeyValue {
+	res := make([]attribute.KeyValue, 0, len(attributes))
+
+	for _, a := range attributes {
+		switch v := a.Value.(type) {
+		case string:
+			res = append(res, attribute.String(a.Key, v))
+		case bool:
+			res = append(res, attribute.Bool(a.Key, v))
+		case int:
+			res = append(res, attribute.Int(a.Key, v))
+		case int64:
+			res = append(res, attribute.Int64(a.Key, v))
+		case float64:
+			res = append(res, attribute.Float64(a.Key, v))
+		case []string:
+			res = append(res, attribute.StringSlice(a.Key, v))
+		case []any:
+			values := make([]string, len(v))
+			for i, value := range v {
+				values[i] = fmt.Sprint(value)
+			}
+			res = append(res, attribute.StringSlice(a.Key, values))
+		default:
+			res = append(res, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,128 @@
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+	"github.com/n-r-w/pgh/v2/px/db/telemetry"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTelemetry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	tel, err := New(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	require.NoError(t, err)
+
+	interceptors := []conn.Interceptor{telemetry.NewInterceptor(tel, telemetry.WithDatabaseName("users"))}
+
+	//nolint:exhaustruct // test
+	require.NoError(t, conn.Run(ctx, interceptors, &conn.QueryInfo{
+		Command: conn.CommandExec,
+		SQL:     "UPDATE users SET name = $1",
+		Args:    []any{"alice"},
+	}, func(context.Context) (int64, error) {
+		return 2, nil
+	}))
+
+	errQuery := &pgconn.PgError{Code: pgerrcode.UniqueViolation} //nolint:exhaustruct // test
+	//nolint:exhaustruct // test
+	require.ErrorIs(t, conn.Run(ctx, interceptors, &conn.QueryInfo{
+		Command: conn.CommandExec,
+		SQL:     "INSERT INTO users (name) VALUES ($1)",
+		Args:    []any{"alice"},
+	}, func(context.Context) (int64, error) {
+		return 0, errQuery
+	}), errQuery)
+
+	// spans
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	require.Equal(t, "pgdb", spans[0].Name())
+	require.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Contains(t, spans[0].Attributes(), attribute.String(telemetry.AttrDBName, "users"))
+	require.Contains(t, spans[0].Attributes(), attribute.String(telemetry.AttrDBOperation, "UPDATE"))
+	require.Contains(t, spans[0].Attributes(), attribute.Int64(telemetry.AttrDBRowsAffected, 2))
+	require.Contains(t, spans[0].Attributes(), attribute.StringSlice("query.arg.", []string{"alice"}))
+
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Contains(t, spans[1].Attributes(), attribute.String(telemetry.AttrDBStatusCode, pgerrcode.UniqueViolation))
+
+	// metrics
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	labels := attribute.NewSet(
+		attribute.String(LabelOperation, conn.CommandExec),
+		attribute.String(LabelDatabase, "users"),
+	)
+
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m.Data
+	}
+
+	requests, ok := metrics[MetricRequests].(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, requests.DataPoints, 1)
+	require.Equal(t, labels, requests.DataPoints[0].Attributes)
+	require.Equal(t, int64(2), requests.DataPoints[0].Value)
+
+	errs, ok := metrics[MetricErrors].(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, errs.DataPoints, 1)
+	require.Equal(t, int64(1), errs.DataPoints[0].Value)
+	class, ok := errs.DataPoints[0].Attributes.Value(LabelSQLStateClass)
+	require.True(t, ok)
+	require.Equal(t, pgerrcode.UniqueViolation[:2], class.AsString())
+
+	duration, ok := metrics[MetricDuration].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, duration.DataPoints, 1)
+	require.Equal(t, labels, duration.DataPoints[0].Attributes)
+	require.Equal(t, uint64(2), duration.DataPoints[0].Count)
+}
+
+func TestConvertAttributes(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []attribute.KeyValue{
+		attribute.String("s", "v"),
+		attribute.Bool("b", true),
+		attribute.Int("i", 1),
+		attribute.Int64("i64", 2),
+		attribute.Float64("f", 1.5),
+		attribute.StringSlice("args", []string{"1", "x", "<nil>"}),
+		attribute.String("d", "1s"),
+	}, convertAttributes([]telemetry.Attribute{
+		{Key: "s", Value: "v"},
+		{Key: "b", Value: true},
+		{Key: "i", Value: 1},
+		{Key: "i64", Value: int64(2)},
+		{Key: "f", Value: 1.5},
+		{Key: "args", Value: []any{1, "x", nil}},
+		{Key: "d", Value: time.Second},
+	}))
+}
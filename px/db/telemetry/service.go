@@ -21,6 +21,12 @@ type ISpan interface {
 	End()
 }
 
+// ISpanEventer optional interface of ISpan for adding events to the span.
+// If the span implements it, each query of a batch is added to the batch span as an event.
+type ISpanEventer interface {
+	AddEvent(name string, attributes []Attribute)
+}
+
 // ITelemetry interface for telemetry.
 type ITelemetry interface {
 	// StartSpan starts new span. If returns nil, span is not created.
@@ -35,15 +41,17 @@ type ITelemetry interface {
 
 // Service wrapper for working with DB and sending telemetry.
 type Service struct {
-	parent    db.IStartStopConnector
-	telemetry ITelemetry
+	parent      db.IStartStopConnector
+	interceptor conn.Interceptor
+	telemetry   ITelemetry
 }
 
 // New creates a new Service instance.
-func New(parent db.IStartStopConnector, telemetry ITelemetry) *Service {
+func New(parent db.IStartStopConnector, telemetry ITelemetry, opts ...Option) *Service {
 	return &Service{
-		parent:    parent,
-		telemetry: telemetry,
+		parent:      parent,
+		interceptor: NewInterceptor(telemetry, opts...),
+		telemetry:   telemetry,
 	}
 }
 
@@ -65,33 +73,5 @@ func (s *Service) Connection(ctx context.Context, opt ...conn.ConnectionOption)
 		defer span.End()
 	}
 
-	return newWrapper(s.parent.Connection(ctx, opt...), s.telemetryHelper)
-}
-
-func (s *Service) telemetryHelper(ctx context.Context, command, details string, arguments []any, f func() error) {
-	ctxSpan, span := s.telemetry.StartSpan(ctx, "pgdb")
-	if span != nil {
-		ctx = ctxSpan
-		defer span.End()
-
-		attributes := make([]Attribute, 0, len(arguments)+3) //nolint:mnd // there will be 3 more
-		attributes = append(attributes,
-			Attribute{"command", command},
-			Attribute{"query.arg.", arguments})
-		if details != "" {
-			attributes = append(attributes, Attribute{"details", details})
-		}
-		span.AddAttributes(attributes)
-	}
-
-	startTime := time.Now()
-
-	err := f()
-
-	s.telemetry.ObserveRequestDuration(ctx, time.Since(startTime))
-
-	s.telemetry.ObserveRequest(ctx)
-	if err != nil {
-		s.telemetry.ObserveRequestError(ctx, err)
-	}
+	return newConnectionWrapper(s.parent.Connection(ctx, opt...), s.interceptor)
 }
@@ -0,0 +1,326 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Names of server timeout settings.
+const (
+	settingStatementTimeout = "statement_timeout"
+	settingLockTimeout      = "lock_timeout"
+)
+
+// setting is the value of a PostgreSQL setting.
+type setting struct {
+	name  string
+	value string
+}
+
+// timeoutSettings returns settings of statement_timeout and lock_timeout. Zero values are skipped.
+func timeoutSettings(statementTimeout, lockTimeout time.Duration) []setting {
+	var settings []setting
+
+	if statementTimeout > 0 {
+		settings = append(settings, setting{name: settingStatementTimeout, value: timeoutSetting(statementTimeout)})
+	}
+	if lockTimeout > 0 {
+		settings = append(settings, setting{name: settingLockTimeout, value: timeoutSetting(lockTimeout)})
+	}
+
+	return settings
+}
+
+// setLocalTimeouts sets statement_timeout and lock_timeout until the end of the transaction.
+// Zero values are skipped.
+func setLocalTimeouts(ctx context.Context, tx pgx.Tx, statementTimeout, lockTimeout time.Duration) error {
+	return setLocalSettings(ctx, tx, timeoutSettings(statementTimeout, lockTimeout))
+}
+
+// setLocalSettings sets settings until the end of the transaction.
+func setLocalSettings(ctx context.Context, tx pgx.Tx, settings []setting) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	calls := make([]string, 0, len(settings))
+	args := make([]any, 0, len(settings)*2) //nolint:mnd // name and value of each setting
+	for _, s := range settings {
+		args = append(args, s.name, s.value)
+		calls = append(calls, fmt.Sprintf("set_config($%d, $%d, true)", len(args)-1, len(args)))
+	}
+
+	if _, err := tx.Exec(ctx, "SELECT "+strings.Join(calls, ", "), args...); err != nil {
+		return fmt.Errorf("failed to set timeouts: %w", err)
+	}
+
+	return nil
+}
+
+// replaceLocalSettings sets settings until the end of the transaction and returns their previous values.
+func replaceLocalSettings(ctx context.Context, tx pgx.Tx, settings []setting) ([]setting, error) {
+	var (
+		current = make([]string, 0, len(settings))
+		calls   = make([]string, 0, len(settings))
+		args    = make([]any, 0, len(settings)*2) //nolint:mnd // name and value of each setting
+		prev    = make([]setting, len(settings))
+		dest    = make([]any, 0, len(settings)*2) //nolint:mnd // previous value and result of set_config
+	)
+
+	for i, s := range settings {
+		args = append(args, s.name, s.value)
+		current = append(current, fmt.Sprintf("current_setting($%d) AS s%d", len(args)-1, i))
+		calls = append(calls, fmt.Sprintf("set_config($%d, $%d, true)", len(args)-1, len(args)))
+		prev[i].name = s.name
+		dest = append(dest, &prev[i].value)
+	}
+	for range settings {
+		dest = append(dest, nil) // results of set_config are skipped
+	}
+
+	// the materialized CTE reads previous values before set_config is called
+	sql := "WITH prev AS MATERIALIZED (SELECT " + strings.Join(current, ", ") + ") SELECT prev.*, " +
+		strings.Join(calls, ", ") + " FROM prev"
+
+	if err := tx.QueryRow(ctx, sql, args...).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to set timeouts: %w", err)
+	}
+
+	return prev, nil
+}
+
+// timeoutSetting converts timeout to the value of a PostgreSQL setting in milliseconds.
+// Zero disables the timeout in PostgreSQL, so values less than a millisecond are rounded up.
+func timeoutSetting(timeout time.Duration) string {
+	return strconv.FormatInt(max(timeout.Milliseconds(), 1), 10) + "ms"
+}
+
+// queryContext returns the context for a query limited by the statement timeout of the connection
+// or the default query timeout of PxDB.
+func (i *Wrapper) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := i.statementTimeout
+	if timeout <= 0 {
+		timeout = i.db.queryTimeout
+	}
+
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// querier executes a statement. It is implemented by pgx.Tx and *pgxpool.Pool.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string,
+		rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// startStatement prepares execution of one statement of the connection: limits the context with the
+// query timeout and sets server timeouts of the connection. pool is used without a transaction.
+// Returns the querier for the statement and finish, that must be called with the error of the statement
+// when it is completed. finish returns the error of the statement or the error of completion.
+//
+// In a transaction, server timeouts are set before the statement and finish restores the previous values,
+// so the timeouts of the connection don't affect other statements of the transaction.
+// Without a transaction, the statement is executed in its own transaction with server timeouts,
+// finish commits it.
+func (i *Wrapper) startStatement(ctx context.Context, pool *pgxpool.Pool,
+) (context.Context, querier, func(error) error, error) {
+	ctx, cancel := i.queryContext(ctx)
+
+	q, complete, err := i.setStatementTimeouts(ctx, pool)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+
+	var (
+		once sync.Once
+		res  error
+	)
+	finish := func(err error) error {
+		once.Do(func() {
+			defer cancel()
+			res = complete(err)
+		})
+		return res
+	}
+
+	return ctx, q, finish, nil
+}
+
+// setStatementTimeouts sets server timeouts of the connection for one statement, see startStatement.
+func (i *Wrapper) setStatementTimeouts(ctx context.Context, pool *pgxpool.Pool,
+) (querier, func(error) error, error) {
+	settings := timeoutSettings(i.statementTimeout, i.lockTimeout)
+
+	if i.tx != nil {
+		if len(settings) == 0 {
+			return i.tx, statementResult, nil
+		}
+
+		prev, err := replaceLocalSettings(ctx, i.tx, settings)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return i.tx, func(err error) error {
+			// the transaction is aborted if the statement failed, so the error of restoring is not important
+			errRestore := setLocalSettings(context.WithoutCancel(ctx), i.tx, prev)
+			if statementError(err) == nil && errRestore != nil {
+				return errRestore
+			}
+			return err
+		}, nil
+	}
+
+	if len(settings) == 0 {
+		return pool, statementResult, nil
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin statement transaction: %w", err)
+	}
+
+	if err = setLocalSettings(ctx, tx, settings); err != nil {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+		return nil, nil, err
+	}
+
+	return tx, func(err error) error {
+		if statementError(err) != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			return err
+		}
+
+		if errCommit := tx.Commit(context.WithoutCancel(ctx)); errCommit != nil {
+			return fmt.Errorf("failed to commit statement transaction: %w", errCommit)
+		}
+		return err
+	}, nil
+}
+
+// statementResult returns the error of the statement.
+func statementResult(err error) error {
+	return err
+}
+
+// statementError returns the error if the statement failed. pgx.ErrNoRows is returned by Scan of pgx.Row
+// for a successful statement, so it doesn't fail the statement.
+func statementError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+// statementRows finishes the statement when rows are read or closed.
+type statementRows struct {
+	pgx.Rows
+	finish func(error) error
+	err    error
+}
+
+func newStatementRows(rows pgx.Rows, finish func(error) error) pgx.Rows {
+	return &statementRows{Rows: rows, finish: finish, err: nil}
+}
+
+// Next prepares the next row for reading. The statement is finished when there are no more rows.
+func (r *statementRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+
+	r.complete()
+	return false
+}
+
+// Close closes rows and finishes the statement.
+func (r *statementRows) Close() {
+	r.complete()
+}
+
+// Err returns the error of the query or the error of finishing the statement.
+func (r *statementRows) Err() error {
+	if err := r.Rows.Err(); err != nil {
+		return err
+	}
+	return r.err
+}
+
+func (r *statementRows) complete() {
+	r.Rows.Close()
+	r.err = r.finish(r.Rows.Err())
+}
+
+// statementRow finishes the statement after Scan.
+type statementRow struct {
+	row    pgx.Row
+	finish func(error) error
+}
+
+// Scan reads the values and finishes the statement.
+func (r *statementRow) Scan(dest ...any) error {
+	return r.finish(r.row.Scan(dest...))
+}
+
+// statementBatchResults finishes the statement when batch results are closed.
+type statementBatchResults struct {
+	pgx.BatchResults
+	finish func(error) error
+}
+
+// Close closes batch results and finishes the statement.
+func (r *statementBatchResults) Close() error {
+	return r.finish(r.BatchResults.Close())
+}
+
+// errRow is pgx.Row that returns an error.
+type errRow struct {
+	err error
+}
+
+// Scan returns the error.
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+// errBatchResults is pgx.BatchResults that returns an error.
+type errBatchResults struct {
+	err error
+}
+
+// Exec returns the error.
+func (r errBatchResults) Exec() (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, r.err
+}
+
+// Query returns the error.
+func (r errBatchResults) Query() (pgx.Rows, error) {
+	return nil, r.err
+}
+
+// QueryRow returns pgx.Row that returns the error.
+func (r errBatchResults) QueryRow() pgx.Row {
+	return errRow(r)
+}
+
+// Close returns the error.
+func (r errBatchResults) Close() error {
+	return r.err
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+	"github.com/n-r-w/pgh/v2/txmgr"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTimeoutSetting(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "1500ms", timeoutSetting(1500*time.Millisecond))
+	require.Equal(t, "1ms", timeoutSetting(time.Microsecond))
+}
+
+func TestWrapper_QueryContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pxDB := New(WithQueryTimeout(time.Minute))
+
+	ctxQuery, cancel := pxDB.Connection(ctx).(*Wrapper).queryContext(ctx)
+	defer cancel()
+	deadline, ok := ctxQuery.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	ctxQuery, cancel = pxDB.Connection(ctx, conn.WithStatementTimeout(time.Second)).(*Wrapper).queryContext(ctx)
+	defer cancel()
+	deadline, ok = ctxQuery.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(time.Second), deadline, time.Second)
+
+	ctxQuery, cancel = New().Connection(ctx).(*Wrapper).queryContext(ctx)
+	defer cancel()
+	_, ok = ctxQuery.Deadline()
+	require.False(t, ok)
+}
+
+func TestWrapper_StatementTimeouts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mc := gomock.NewController(t)
+	tx := px.NewMockTx(mc)
+	prevRow := px.NewMockRow(mc)
+	row := px.NewMockRow(mc)
+
+	w := newDatabaseWrapperWithTran(New(), tx, txmgr.Options{}, false, 3*time.Second, 0)
+
+	gomock.InOrder(
+		tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), settingStatementTimeout, "3000ms").Return(prevRow),
+		prevRow.EXPECT().Scan(gomock.Any(), nil).SetArg(0, "2s").Return(nil),
+		tx.EXPECT().QueryRow(gomock.Any(), "SELECT 1").Return(row),
+		row.EXPECT().Scan(gomock.Any()).Return(pgx.ErrNoRows),
+		// the previous value is restored after the statement
+		tx.EXPECT().Exec(gomock.Any(), "SELECT set_config($1, $2, true)", settingStatementTimeout, "2s").
+			Return(pgconn.CommandTag{}, nil),
+	)
+
+	var v int
+	require.ErrorIs(t, w.QueryRow(ctx, "SELECT 1").Scan(&v), pgx.ErrNoRows)
+}
+
+func TestPxDB_Timeouts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, informer := testdock.GetPgxPool(t, testdock.DefaultPostgresDSN)
+
+	pxDB := New(
+		WithName("timeouts"),
+		WithDSN(informer.DSN()),
+	)
+
+	ctxStart, cancelStart := context.WithTimeout(ctx, 5*time.Second)
+	t.Cleanup(cancelStart)
+	require.NoError(t, pxDB.Start(ctxStart))
+	t.Cleanup(func() {
+		_ = pxDB.Stop(ctx)
+	})
+
+	tm := txmgr.New(pxDB, pxDB)
+
+	show := func(ctx context.Context, con conn.IConnection, name string) string {
+		var value string
+		require.NoError(t, con.QueryRow(ctx, "SHOW "+name).Scan(&value))
+		return value
+	}
+
+	// transaction options
+	err := tm.Begin(ctx, func(ctxTr context.Context) error {
+		con := pxDB.Connection(ctxTr)
+		require.Equal(t, "2s", show(ctxTr, con, "statement_timeout"))
+		require.Equal(t, "500ms", show(ctxTr, con, "lock_timeout"))
+
+		// statement is canceled by the server
+		_, errTr := con.Exec(ctxTr, "SELECT pg_sleep(5)")
+		return errTr
+	}, txmgr.WithStatementTimeout(2*time.Second), txmgr.WithServerLockTimeout(500*time.Millisecond))
+	require.True(t, px.IsQueryCanceled(err))
+
+	// connection options
+	require.NoError(t, tm.Begin(ctx, func(ctxTr context.Context) error {
+		con := pxDB.Connection(ctxTr, conn.WithStatementTimeout(3*time.Second), conn.WithLockTimeout(time.Second))
+		require.Equal(t, "3s", show(ctxTr, con, "statement_timeout"))
+		require.Equal(t, "1s", show(ctxTr, con, "lock_timeout"))
+
+		// other connections of the transaction keep the transaction settings
+		other := pxDB.Connection(ctxTr)
+		require.Equal(t, "2s", show(ctxTr, other, "statement_timeout"))
+		require.Equal(t, "500ms", show(ctxTr, other, "lock_timeout"))
+
+		require.Equal(t, "3s", show(ctxTr, con, "statement_timeout"))
+		return nil
+	}, txmgr.WithStatementTimeout(2*time.Second), txmgr.WithServerLockTimeout(500*time.Millisecond)))
+
+	// connection options without transaction
+	con := pxDB.Connection(ctx, conn.WithPrimary(), conn.WithStatementTimeout(3*time.Second),
+		conn.WithLockTimeout(time.Second))
+	require.Equal(t, "3s", show(ctx, con, "statement_timeout"))
+	require.Equal(t, "1s", show(ctx, con, "lock_timeout"))
+
+	// settings are local to the statement
+	con = pxDB.Connection(ctx, conn.WithPrimary())
+	require.Equal(t, "0", show(ctx, con, "statement_timeout"))
+	require.Equal(t, "0", show(ctx, con, "lock_timeout"))
+
+	// client-side timeout without transaction
+	_, err = pxDB.Connection(ctx, conn.WithStatementTimeout(100*time.Millisecond)).Exec(ctx, "SELECT pg_sleep(5)")
+	require.Error(t, err)
+}
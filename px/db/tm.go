@@ -4,14 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
 	"github.com/n-r-w/pgh/v2/txmgr"
 )
 
 func (p *PxDB) beginTxHelper(ctx context.Context, opts txmgr.Options) (*pgxpool.Conn, pgx.Tx, error) {
-	con, err := p.pool.Acquire(ctx)
+	con, err := p.txPool(opts).Acquire(ctx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to acquire connection: %w", err)
 	}
@@ -35,63 +40,175 @@ func (p *PxDB) beginTxHelper(ctx context.Context, opts txmgr.Options) (*pgxpool.
 		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
+	if err := setLocalTimeouts(ctx, tx, opts.StatementTimeout, opts.ServerLockTimeout); err != nil {
+		_ = tx.Rollback(ctx)
+		con.Release()
+		return nil, nil, err
+	}
+
+	if err := acquireAdvisoryLocks(ctx, tx, opts); err != nil {
+		_ = tx.Rollback(ctx)
+		con.Release()
+		return nil, nil, err
+	}
+
 	return con, tx, nil
 }
 
 // Begin runs a function within a transaction.
 func (p *PxDB) Begin(ctx context.Context, f func(ctxTr context.Context) error, opts txmgr.Options) (err error) {
-	con, tx, err := p.beginTxHelper(ctx, opts)
+	con, tx, err := p.beginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
 
+	// Create transaction object
+	t := newTransaction(p, tx, opts)
+
+	// finished is true if commit or rollback has been executed
+	finished := false
+	rollback := func() error {
+		finished = true
+		return p.rollbackTx(ctx, tx, opts)
+	}
+
 	// If panic occurs, rollback the transaction.
 	defer func() {
 		defer con.Release()
 
 		if rec := recover(); rec != nil {
-			_ = tx.Rollback(ctx)
+			if !finished {
+				_ = rollback()
+			}
+			t.runRollbackHooks(ctx)
 			panic(rec) // Re-throw panic after rollback.
 		}
 	}()
 
 	defer func() {
-		errRollback := tx.Rollback(ctx)
-		if errRollback != nil && !errors.Is(errRollback, pgx.ErrTxClosed) {
-			if err != nil {
-				err = fmt.Errorf("%w (rollback error: %v)", err, errRollback) //nolint:errorlint // ok for 2 errors
-			} else {
-				err = errRollback
+		if !finished {
+			errRollback := rollback()
+			if errRollback != nil && !errors.Is(errRollback, pgx.ErrTxClosed) {
+				if err != nil {
+					err = fmt.Errorf("%w (rollback error: %v)", err, errRollback) //nolint:errorlint // ok for 2 errors
+				} else {
+					err = errRollback
+				}
 			}
 		}
+
+		if err != nil {
+			t.runRollbackHooks(ctx)
+		}
 	}()
 
-	// Create transaction object and put it in context
-	tCtx := newTransaction(p, tx, opts).toContext(ctx)
+	// Put transaction object in context
+	tCtx := t.toContext(ctx)
 
 	if err = f(tCtx); err != nil {
 		return err
 	}
 
-	return tx.Commit(ctx)
+	// the transaction is closed even if commit fails or is blocked by an interceptor
+	finished = true
+	if err = p.commitTx(ctx, tx, opts); err != nil {
+		return err
+	}
+
+	t.runCommitHooks(ctx)
+
+	return nil
 }
 
 // BeginTx begins a new transaction with the provided options.
 func (p *PxDB) BeginTx(ctx context.Context, opts txmgr.Options) (context.Context, txmgr.ITransactionFinisher, error) {
-	con, tx, err := p.beginTxHelper(ctx, opts)
+	con, tx, err := p.beginTx(ctx, opts)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Create transaction object and put it in context
-	tCtx := newTransaction(p, tx, opts).toContext(ctx)
+	t := newTransaction(p, tx, opts)
+	tCtx := t.toContext(ctx)
 
 	return tCtx, &transactionFinisher{
 		con: con,
 		tx:  tx,
+		t:   t,
 	}, nil
 }
 
+// beginTx starts a transaction calling interceptors of PxDB.
+func (p *PxDB) beginTx(ctx context.Context, opts txmgr.Options) (con *pgxpool.Conn, tx pgx.Tx, err error) {
+	err = p.interceptTx(ctx, conn.CommandBegin, false, opts, func(ctx context.Context) error {
+		con, tx, err = p.beginTxHelper(ctx, opts)
+		return err
+	})
+
+	return con, tx, err
+}
+
+// commitTx commits a transaction calling interceptors of PxDB.
+// If Before blocks the commit, the transaction is rolled back, so it is always finished.
+func (p *PxDB) commitTx(ctx context.Context, tx pgx.Tx, opts txmgr.Options) error {
+	called := false
+
+	err := p.interceptTx(ctx, conn.CommandCommit, true, opts, func(ctx context.Context) error {
+		called = true
+		return tx.Commit(ctx)
+	})
+	if !called {
+		if errRollback := p.rollbackTx(ctx, tx, opts); errRollback != nil {
+			return fmt.Errorf("%w (rollback error: %v)", err, errRollback) //nolint:errorlint // ok for 2 errors
+		}
+	}
+
+	return err
+}
+
+// rollbackTx rolls back a transaction calling interceptors of PxDB.
+// Interceptors can't block rollback: the transaction is rolled back even if Before returns an error.
+func (p *PxDB) rollbackTx(ctx context.Context, tx pgx.Tx, opts txmgr.Options) error {
+	called := false
+
+	err := p.interceptTx(ctx, conn.CommandRollback, true, opts, func(ctx context.Context) error {
+		called = true
+		return tx.Rollback(ctx)
+	})
+	if !called {
+		return tx.Rollback(ctx)
+	}
+
+	return err
+}
+
+// interceptTx calls interceptors of PxDB and the query logger around the transaction operation f.
+func (p *PxDB) interceptTx(ctx context.Context, command string, inTransaction bool, opts txmgr.Options,
+	f func(ctx context.Context) error,
+) error {
+	interceptors := slices.Clip(p.interceptors)
+	if p.logQueries {
+		interceptors = append(interceptors, NewLogInterceptor(p.logger, p.name, p.argPolicy))
+	}
+
+	if len(interceptors) == 0 {
+		return f(ctx)
+	}
+
+	//nolint:exhaustruct // results are set by conn.Run
+	q := &conn.QueryInfo{
+		Command:            command,
+		SQL:                strings.ToUpper(command),
+		InTransaction:      inTransaction,
+		TransactionOptions: opts,
+		Start:              time.Now(),
+	}
+
+	return conn.Run(ctx, interceptors, q, func(ctx context.Context) (int64, error) {
+		return 0, f(ctx)
+	})
+}
+
 // InTransaction returns true if transaction is started.
 func (p *PxDB) InTransaction(ctx context.Context) bool {
 	_, ok := txFromContext(ctx)
@@ -156,14 +273,22 @@ type transaction struct {
 	db   *PxDB
 	tx   pgx.Tx
 	opts txmgr.Options
+
+	// callbacks executed after the transaction is finished
+	mu         sync.Mutex
+	onCommit   []func(ctx context.Context)
+	onRollback []func(ctx context.Context)
+	finished   bool
 }
 
+var _ txmgr.ITransactionHooks = (*transaction)(nil)
+
 func newTransaction(db *PxDB, tx pgx.Tx, opts txmgr.Options) *transaction {
 	if db == nil || tx == nil {
 		panic("invalid arguments") // just in case
 	}
 
-	return &transaction{
+	return &transaction{ //nolint:exhaustruct // hooks are added later
 		db:   db,
 		tx:   tx,
 		opts: opts,
@@ -172,12 +297,80 @@ func newTransaction(db *PxDB, tx pgx.Tx, opts txmgr.Options) *transaction {
 
 // toContext puts transaction in context.
 func (t *transaction) toContext(ctx context.Context) context.Context {
-	return context.WithValue(ctx, txKey, t)
+	return txmgr.ContextWithHooks(context.WithValue(ctx, txKey, t), t)
 }
 
 // removeFromContext removes transaction from context.
 func (t *transaction) removeFromContext(ctx context.Context) context.Context {
-	return context.WithValue(ctx, txKey, nil)
+	return txmgr.ContextWithHooks(context.WithValue(ctx, txKey, nil), nil)
+}
+
+// OnCommit registers a function that is called after the transaction is committed.
+// Implements txmgr.ITransactionHooks.
+func (t *transaction) OnCommit(fn func(ctx context.Context)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.onCommit = append(t.onCommit, fn)
+}
+
+// OnRollback registers a function that is called after the transaction is rolled back.
+// Implements txmgr.ITransactionHooks.
+func (t *transaction) OnRollback(fn func(ctx context.Context)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.onRollback = append(t.onRollback, fn)
+}
+
+// runCommitHooks calls commit callbacks once with ctx without transaction. Rollback callbacks are discarded.
+func (t *transaction) runCommitHooks(ctx context.Context) {
+	ctx = WithoutTransaction(ctx)
+	for _, fn := range t.finishHooks(true) {
+		fn(ctx)
+	}
+}
+
+// runRollbackHooks calls rollback callbacks once with ctx without transaction. Commit callbacks are discarded.
+// ctx of a savepoint contains the outer transaction, which is removed too.
+func (t *transaction) runRollbackHooks(ctx context.Context) {
+	ctx = WithoutTransaction(ctx)
+	for _, fn := range t.finishHooks(false) {
+		fn(ctx)
+	}
+}
+
+// moveHooks passes callbacks of a released savepoint to the outer transaction.
+func (t *transaction) moveHooks(parent *transaction) {
+	t.mu.Lock()
+	onCommit, onRollback := t.onCommit, t.onRollback
+	t.onCommit, t.onRollback, t.finished = nil, nil, true
+	t.mu.Unlock()
+
+	parent.mu.Lock()
+	defer parent.mu.Unlock()
+
+	parent.onCommit = append(parent.onCommit, onCommit...)
+	parent.onRollback = append(parent.onRollback, onRollback...)
+}
+
+// finishHooks marks the transaction as finished and returns callbacks to run.
+func (t *transaction) finishHooks(committed bool) []func(ctx context.Context) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.finished {
+		return nil
+	}
+
+	hooks := t.onRollback
+	if committed {
+		hooks = t.onCommit
+	}
+
+	t.onCommit, t.onRollback, t.finished = nil, nil, true
+
+	return hooks
 }
 
 // txFromContext extracts transaction from context.
@@ -205,16 +398,35 @@ func WithoutTransaction(ctx context.Context) context.Context {
 type transactionFinisher struct {
 	con *pgxpool.Conn
 	tx  pgx.Tx
+	t   *transaction
 }
 
 // Commit commits the transaction.
 func (t *transactionFinisher) Commit(ctx context.Context) error {
 	defer t.con.Release()
-	return t.tx.Commit(ctx)
+
+	if err := t.t.db.commitTx(ctx, t.tx, t.t.opts); err != nil {
+		// the transaction is rolled back if commit fails
+		t.t.runRollbackHooks(ctx)
+		return err
+	}
+
+	t.t.runCommitHooks(ctx)
+
+	return nil
 }
 
 // Rollback rolls back the transaction.
 func (t *transactionFinisher) Rollback(ctx context.Context) error {
 	defer t.con.Release()
-	return t.tx.Rollback(ctx)
+
+	err := t.t.db.rollbackTx(ctx, t.tx, t.t.opts)
+	if errors.Is(err, pgx.ErrTxClosed) {
+		// already finished
+		return err
+	}
+
+	t.t.runRollbackHooks(ctx)
+
+	return err
 }
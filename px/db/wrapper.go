@@ -2,42 +2,80 @@ package db
 
 import (
 	"context"
-	"fmt"
-	"strings"
+	"slices"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
 	"github.com/n-r-w/pgh/v2/txmgr"
-	"go.opentelemetry.io/otel/trace"
 )
 
 // Wrapper is a wrapper over pgx.
 type Wrapper struct {
 	logQueries bool
+	primary    bool
 	txOpts     txmgr.Options
 	db         *PxDB
 	tx         pgx.Tx
+
+	// timeouts of the connection, see conn.WithStatementTimeout and conn.WithLockTimeout
+	statementTimeout time.Duration
+	lockTimeout      time.Duration
+
+	// con executes queries through interceptors of PxDB, see WithInterceptors
+	con conn.IConnection
 }
 
 // newDatabaseWrapperNoTran creates databaseWrapper for working without transaction.
-func newDatabaseWrapperNoTran(db *PxDB, logQueries bool) *Wrapper {
-	return &Wrapper{
-		db:         db,
-		tx:         nil,
-		txOpts:     txmgr.Options{Level: 0, Mode: 0, Lock: false},
-		logQueries: logQueries,
-	}
+// If primary is false, reads can be routed to replicas.
+func newDatabaseWrapperNoTran(db *PxDB, logQueries, primary bool,
+	statementTimeout, lockTimeout time.Duration,
+) *Wrapper {
+	return (&Wrapper{
+		db: db,
+		tx: nil,
+		//nolint:exhaustruct // external type, zero values are acceptable defaults
+		txOpts:           txmgr.Options{},
+		logQueries:       logQueries,
+		primary:          primary,
+		statementTimeout: statementTimeout,
+		lockTimeout:      lockTimeout,
+		con:              nil,
+	}).intercept()
 }
 
 // newDatabaseWrapperWithTran creates databaseWrapper for working with transaction.
-func newDatabaseWrapperWithTran(db *PxDB, tx pgx.Tx, txOpts txmgr.Options, logQueries bool) *Wrapper {
-	return &Wrapper{
-		db:         db,
-		tx:         tx,
-		txOpts:     txOpts,
-		logQueries: logQueries,
+func newDatabaseWrapperWithTran(db *PxDB, tx pgx.Tx, txOpts txmgr.Options, logQueries bool,
+	statementTimeout, lockTimeout time.Duration,
+) *Wrapper {
+	return (&Wrapper{
+		db:               db,
+		tx:               tx,
+		txOpts:           txOpts,
+		logQueries:       logQueries,
+		primary:          true,
+		statementTimeout: statementTimeout,
+		lockTimeout:      lockTimeout,
+		con:              nil,
+	}).intercept()
+}
+
+// intercept sets the connection that calls interceptors of PxDB and the query loggers around pgx.
+func (i *Wrapper) intercept() *Wrapper {
+	// loggers are the innermost interceptors, so they log queries changed by other interceptors
+	interceptors := slices.Clip(i.db.interceptors)
+	if i.db.slowQuery != nil {
+		interceptors = append(interceptors, i.db.slowQuery)
+	}
+	if i.logQueries {
+		interceptors = append(interceptors, NewLogInterceptor(i.db.logger, i.db.name, i.db.argPolicy))
 	}
+
+	i.con = conn.Intercept((*pgxExecutor)(i), interceptors...)
+
+	return i
 }
 
 // InTransaction returns true if transaction is started.
@@ -48,7 +86,8 @@ func (i *Wrapper) InTransaction() bool {
 // TransactionOptions returns transaction parameters. If transaction is not started, returns false.
 func (i *Wrapper) TransactionOptions() txmgr.Options {
 	if i.tx == nil {
-		return txmgr.Options{Level: 0, Mode: 0, Lock: false}
+		//nolint:exhaustruct // external type, zero values are acceptable defaults
+		return txmgr.Options{}
 	}
 	return i.txOpts
 }
@@ -61,45 +100,18 @@ func (i *Wrapper) WithoutTransaction(ctx context.Context) context.Context {
 // CopyFrom implements bulk data insertion into a table.
 func (i *Wrapper) CopyFrom(ctx context.Context, tableName pgx.Identifier,
 	columnNames []string, rowSrc pgx.CopyFromSource,
-) (n int64, err error) {
-	i.logQueryHelper(ctx, fmt.Sprintf("COPY %s (%s)", tableName, strings.Join(columnNames, ", ")), "", nil, func() error {
-		if i.tx != nil {
-			n, err = i.tx.CopyFrom(ctx, tableName, columnNames, rowSrc)
-		} else {
-			n, err = i.db.pool.CopyFrom(ctx, tableName, columnNames, rowSrc)
-		}
-		return err
-	})
-
-	return n, err
+) (int64, error) {
+	return i.con.CopyFrom(ctx, tableName, columnNames, rowSrc)
 }
 
 // Exec executes a query without returning data.
-func (i *Wrapper) Exec(ctx context.Context, sql string, args ...any) (tag pgconn.CommandTag, err error) {
-	i.logQueryHelper(ctx, "Exec", sql, args, func() error {
-		if i.tx != nil {
-			tag, err = i.tx.Exec(ctx, sql, args...)
-		} else {
-			tag, err = i.db.pool.Exec(ctx, sql, args...)
-		}
-		return err
-	})
-
-	return tag, err
+func (i *Wrapper) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return i.con.Exec(ctx, sql, args...)
 }
 
 // Query executes a query and returns the result.
-func (i *Wrapper) Query(ctx context.Context, sql string, args ...any) (rows pgx.Rows, err error) {
-	i.logQueryHelper(ctx, "Query", sql, args, func() error {
-		if i.tx != nil {
-			rows, err = i.tx.Query(ctx, sql, args...) //nolint:sqlclosecheck // will be closed by caller
-		} else {
-			rows, err = i.db.pool.Query(ctx, sql, args...) //nolint:sqlclosecheck // will be closed by caller
-		}
-		return err
-	})
-
-	return rows, err
+func (i *Wrapper) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return i.con.Query(ctx, sql, args...)
 }
 
 // QueryRow gets a connection and executes a query that should return no more than one row.
@@ -107,55 +119,13 @@ func (i *Wrapper) Query(ctx context.Context, sql string, args ...any) (rows pgx.
 // pgx.Row.Scan will return pgx.ErrNoRows.
 // Otherwise, pgx.Row.Scan scans the first selected row and discards the rest.
 // The obtained connection is returned to the pool when the pgx.Row.Scan method is called.
-func (i *Wrapper) QueryRow(ctx context.Context, sql string, args ...any) (row pgx.Row) {
-	i.logQueryHelper(ctx, "QueryRow", sql, args, func() error {
-		if i.tx != nil {
-			row = i.tx.QueryRow(ctx, sql, args...)
-		} else {
-			row = i.db.pool.QueryRow(ctx, sql, args...)
-		}
-		return nil
-	})
-
-	return row
+func (i *Wrapper) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return i.con.QueryRow(ctx, sql, args...)
 }
 
 // SendBatch sends a set of queries for execution, combining all queries into one package.
-func (i *Wrapper) SendBatch(ctx context.Context, b *pgx.Batch) (res pgx.BatchResults) {
-	const batchSizeLogLimit = 10
-	var queries strings.Builder
-
-	if i.logQueries {
-		queries.Grow(batchSizeLogLimit)
-		for i, q := range b.QueuedQueries {
-			if i > batchSizeLogLimit {
-				_, _ = queries.WriteString("...")
-				break
-			}
-			// [SELECT * FROM users WHERE id IN ($1,$2); ARGS: 2,3]
-			_, _ = queries.WriteString("[")
-			_, _ = queries.WriteString(q.SQL)
-			_, _ = queries.WriteString("; ARGS: ")
-			for j, arg := range q.Arguments {
-				if j > 0 {
-					_, _ = queries.WriteString(",")
-				}
-				_, _ = queries.WriteString(fmt.Sprintf("%v", arg))
-			}
-			_, _ = queries.WriteString("]")
-		}
-	}
-
-	i.logQueryHelper(ctx, queries.String(), "", nil, func() error {
-		if i.tx != nil {
-			res = i.tx.SendBatch(ctx, b)
-		} else {
-			res = i.db.pool.SendBatch(ctx, b)
-		}
-		return nil
-	})
-
-	return res
+func (i *Wrapper) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return i.con.SendBatch(ctx, b)
 }
 
 // LargeObjects supports working with large objects and is only available within a transaction (PostgreSQL limitation).
@@ -168,37 +138,102 @@ func (i *Wrapper) LargeObjects() pgx.LargeObjects {
 	panic("LargeObjects() is not supported without transaction")
 }
 
-// logQueryHelper performs query logging and calls function f.
-func (i *Wrapper) logQueryHelper(ctx context.Context, command, query string, args []any, f func() error) {
-	if !i.logQueries {
-		_ = f() // we're not interested in the result since it should be handled inside f
-		return
+// readPool returns the pool for a query executed without transaction, see PxDB.readPool.
+func (i *Wrapper) readPool(sql string) *pgxpool.Pool {
+	if i.tx != nil {
+		return nil
 	}
+	return i.db.readPool(i.primary, sql)
+}
 
-	start := time.Now()
+// pgxExecutor executes queries of Wrapper with pgx. Interceptors of Wrapper are called around it.
+type pgxExecutor Wrapper
 
-	err := f()
+// InTransaction returns true if transaction is started.
+func (e *pgxExecutor) InTransaction() bool {
+	return (*Wrapper)(e).InTransaction()
+}
+
+// TransactionOptions returns transaction parameters.
+func (e *pgxExecutor) TransactionOptions() txmgr.Options {
+	return (*Wrapper)(e).TransactionOptions()
+}
+
+// WithoutTransaction returns context without transaction.
+func (e *pgxExecutor) WithoutTransaction(ctx context.Context) context.Context {
+	return WithoutTransaction(ctx)
+}
 
-	attrs := []any{
-		"database", i.db.name,
-		"command", command,
-		"latency", time.Since(start),
-		"args", args,
+// LargeObjects supports working with large objects.
+func (e *pgxExecutor) LargeObjects() pgx.LargeObjects {
+	return (*Wrapper)(e).LargeObjects()
+}
+
+// CopyFrom implements bulk data insertion into a table.
+func (e *pgxExecutor) CopyFrom(ctx context.Context, tableName pgx.Identifier,
+	columnNames []string, rowSrc pgx.CopyFromSource,
+) (int64, error) {
+	w := (*Wrapper)(e)
+
+	ctx, q, finish, err := w.startStatement(ctx, w.db.pool)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := q.CopyFrom(ctx, tableName, columnNames, rowSrc)
+	return n, finish(err)
+}
+
+// Exec executes a query without returning data.
+func (e *pgxExecutor) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	w := (*Wrapper)(e)
+
+	ctx, q, finish, err := w.startStatement(ctx, w.db.pool)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	tag, err := q.Exec(ctx, sql, args...)
+	return tag, finish(err)
+}
+
+// Query executes a query and returns the result.
+func (e *pgxExecutor) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	w := (*Wrapper)(e)
+
+	ctx, q, finish, err := w.startStatement(ctx, w.readPool(sql))
+	if err != nil {
+		return nil, err
 	}
 
-	if query != "" {
-		attrs = append(attrs, "query", query)
+	rows, err := q.Query(ctx, sql, args...) //nolint:sqlclosecheck // will be closed by caller
+	if err != nil {
+		return rows, finish(err)
 	}
 
-	spanContext := trace.SpanFromContext(ctx).SpanContext()
-	if spanContext.TraceID().IsValid() {
-		attrs = append(attrs, "trace_id", spanContext.TraceID().String())
+	return newStatementRows(rows, finish), nil
+}
+
+// QueryRow executes a query that should return no more than one row.
+func (e *pgxExecutor) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	w := (*Wrapper)(e)
+
+	ctx, q, finish, err := w.startStatement(ctx, w.readPool(sql))
+	if err != nil {
+		return errRow{err: err}
 	}
 
+	return &statementRow{row: q.QueryRow(ctx, sql, args...), finish: finish}
+}
+
+// SendBatch sends a set of queries for execution, combining all queries into one package.
+func (e *pgxExecutor) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	w := (*Wrapper)(e)
+
+	ctx, q, finish, err := w.startStatement(ctx, w.db.pool)
 	if err != nil {
-		attrs = append(attrs, "error", err)
-		i.db.logger.Error(ctx, "dbquery", attrs...)
-	} else {
-		i.db.logger.Debug(ctx, "dbquery", attrs...)
+		return errBatchResults{err: err}
 	}
+
+	return &statementBatchResults{BatchResults: q.SendBatch(ctx, b), finish: finish}
 }
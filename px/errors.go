@@ -5,7 +5,7 @@ import (
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2/internal/pgerr"
 )
 
 // Helpers for working with Postgres errors.
@@ -13,44 +13,86 @@ import (
 // If something is missing there, it is added to this file.
 // https://www.postgresql.org/docs/16/errcodes-appendix.html
 
+// Error is returned by query helpers. It contains the operation, truncated SQL and fields of pgconn.PgError:
+// SQLSTATE code and class, schema, table, column and constraint names. Use errors.As or AsError to get it.
+type Error = pgerr.Error
+
+// AsError returns *Error from the error chain.
+func AsError(err error) (*Error, bool) {
+	return pgerr.AsError(err)
+}
+
+// ErrorCode returns the SQLSTATE code of the error or an empty string if the error is not returned by the server.
+func ErrorCode(err error) string {
+	return pgerr.Code(err)
+}
+
+// ErrorClass returns the SQLSTATE class (the first two characters of the code) of the error or an empty string.
+func ErrorClass(err error) string {
+	return pgerr.Class(err)
+}
+
 // IsNoRows checks if the error is a "no rows" error.
 func IsNoRows(err error) bool {
 	if errors.Is(err, pgx.ErrNoRows) {
 		return true
 	}
 
-	if pgErr, ok := toPgError(err); ok {
-		if pgErr.Code == pgerrcode.NoDataFound {
-			return true
-		}
-	}
-	return false
+	return pgerr.IsCode(err, pgerrcode.NoDataFound)
 }
 
 // IsUniqueViolation checks if the error is a unique constraint violation.
 func IsUniqueViolation(err error) bool {
-	if pgErr, ok := toPgError(err); ok {
-		if pgErr.Code == pgerrcode.UniqueViolation {
-			return true
-		}
-	}
-	return false
+	return pgerr.IsUniqueViolation(err)
 }
 
 // IsForeignKeyViolation checks if the error is a foreign key constraint violation.
 func IsForeignKeyViolation(err error) bool {
-	if pgErr, ok := toPgError(err); ok {
-		if pgErr.Code == pgerrcode.ForeignKeyViolation {
-			return true
-		}
-	}
-	return false
+	return pgerr.IsForeignKeyViolation(err)
 }
 
-func toPgError(err error) (*pgconn.PgError, bool) {
-	var pgErr *pgconn.PgError
-	if errors.As(err, &pgErr) {
-		return pgErr, true
-	}
-	return nil, false
+// IsNotNullViolation checks if the error is a not-null constraint violation.
+func IsNotNullViolation(err error) bool {
+	return pgerr.IsNotNullViolation(err)
+}
+
+// IsCheckViolation checks if the error is a check constraint violation.
+func IsCheckViolation(err error) bool {
+	return pgerr.IsCheckViolation(err)
+}
+
+// IsConflict checks if the error is caused by a conflict with existing data: unique or exclusion constraint violation.
+func IsConflict(err error) bool {
+	return pgerr.IsConflict(err)
+}
+
+// IsSerializationFailure checks if the error is a serialization failure.
+// Transactions with TxRepeatableRead and TxSerializable levels can be retried after such errors.
+func IsSerializationFailure(err error) bool {
+	return pgerr.IsSerializationFailure(err)
+}
+
+// IsDeadlock checks if the error is a deadlock detected error.
+func IsDeadlock(err error) bool {
+	return pgerr.IsDeadlock(err)
+}
+
+// IsRetryable checks if the transaction can be retried after the error: serialization failure or deadlock.
+func IsRetryable(err error) bool {
+	return pgerr.IsRetryable(err)
+}
+
+// IsLockTimeout checks if a lock could not be acquired because lock_timeout expired or NOWAIT was used.
+func IsLockTimeout(err error) bool {
+	return pgerr.IsLockTimeout(err)
+}
+
+// IsQueryCanceled checks if the query was canceled by the server, e.g. because statement_timeout expired.
+func IsQueryCanceled(err error) bool {
+	return pgerr.IsQueryCanceled(err)
+}
+
+// IsConnectionLost checks if the error is caused by a lost or unavailable connection.
+func IsConnectionLost(err error) bool {
+	return pgerr.IsConnectionLost(err)
 }
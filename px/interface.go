@@ -25,3 +25,14 @@ type IBatcher interface {
 type ITransactionBeginner interface {
 	BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error)
 }
+
+// ICopier is a subset of pgxpool.Pool, pgx.Conn and pgx.Tx interfaces for COPY.
+type ICopier interface {
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// ICopyQuerier is a subset of pgx.Conn and pgx.Tx interfaces for COPY combined with other queries.
+type ICopyQuerier interface {
+	IQuerier
+	ICopier
+}
@@ -155,3 +155,119 @@ func (mr *MockITransactionBeginnerMockRecorder) BeginTx(arg0, arg1 any) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTx", reflect.TypeOf((*MockITransactionBeginner)(nil).BeginTx), arg0, arg1)
 }
+
+// MockICopier is a mock of ICopier interface.
+type MockICopier struct {
+	ctrl     *gomock.Controller
+	recorder *MockICopierMockRecorder
+}
+
+// MockICopierMockRecorder is the mock recorder for MockICopier.
+type MockICopierMockRecorder struct {
+	mock *MockICopier
+}
+
+// NewMockICopier creates a new mock instance.
+func NewMockICopier(ctrl *gomock.Controller) *MockICopier {
+	mock := &MockICopier{ctrl: ctrl}
+	mock.recorder = &MockICopierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockICopier) EXPECT() *MockICopierMockRecorder {
+	return m.recorder
+}
+
+// CopyFrom mocks base method.
+func (m *MockICopier) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFrom", ctx, tableName, columnNames, rowSrc)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyFrom indicates an expected call of CopyFrom.
+func (mr *MockICopierMockRecorder) CopyFrom(ctx, tableName, columnNames, rowSrc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFrom", reflect.TypeOf((*MockICopier)(nil).CopyFrom), ctx, tableName, columnNames, rowSrc)
+}
+
+// MockICopyQuerier is a mock of ICopyQuerier interface.
+type MockICopyQuerier struct {
+	ctrl     *gomock.Controller
+	recorder *MockICopyQuerierMockRecorder
+}
+
+// MockICopyQuerierMockRecorder is the mock recorder for MockICopyQuerier.
+type MockICopyQuerierMockRecorder struct {
+	mock *MockICopyQuerier
+}
+
+// NewMockICopyQuerier creates a new mock instance.
+func NewMockICopyQuerier(ctrl *gomock.Controller) *MockICopyQuerier {
+	mock := &MockICopyQuerier{ctrl: ctrl}
+	mock.recorder = &MockICopyQuerierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockICopyQuerier) EXPECT() *MockICopyQuerierMockRecorder {
+	return m.recorder
+}
+
+// CopyFrom mocks base method.
+func (m *MockICopyQuerier) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFrom", ctx, tableName, columnNames, rowSrc)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyFrom indicates an expected call of CopyFrom.
+func (mr *MockICopyQuerierMockRecorder) CopyFrom(ctx, tableName, columnNames, rowSrc any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFrom", reflect.TypeOf((*MockICopyQuerier)(nil).CopyFrom), ctx, tableName, columnNames, rowSrc)
+}
+
+// Exec mocks base method.
+func (m *MockICopyQuerier) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, sql}
+	for _, a := range arguments {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Exec", varargs...)
+	ret0, _ := ret[0].(pgconn.CommandTag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exec indicates an expected call of Exec.
+func (mr *MockICopyQuerierMockRecorder) Exec(ctx, sql any, arguments ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, sql}, arguments...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockICopyQuerier)(nil).Exec), varargs...)
+}
+
+// Query mocks base method.
+func (m *MockICopyQuerier) Query(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, query}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Query", varargs...)
+	ret0, _ := ret[0].(pgx.Rows)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockICopyQuerierMockRecorder) Query(ctx, query any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, query}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockICopyQuerier)(nil).Query), varargs...)
+}
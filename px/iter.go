@@ -0,0 +1,66 @@
+package px
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/internal/pgerr"
+	sq "github.com/n-r-w/squirrel"
+)
+
+// Iter executes a query and returns an iterator over rows scanned into T. Querier can be either pgx.Tx or pg_types.Pool.
+// Unlike Select, rows are not loaded into memory. Rows are closed when the iteration is finished or stopped by break.
+// If an error occurs, it is returned with the zero value of T and the iteration stops.
+//
+//	for v, err := range px.Iter[User](ctx, tx, query) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func Iter[T any](ctx context.Context, querier IQuerier, sqlizer sq.Sqlizer) iter.Seq2[T, error] {
+	sql, args, err := sqToSQL(ctx, sqlizer)
+	if err != nil {
+		return func(yield func(T, error) bool) {
+			var zero T
+			yield(zero, fmt.Errorf("pgx.Iter to sql: %w", err))
+		}
+	}
+
+	return IterPlain[T](ctx, querier, sql, args)
+}
+
+// IterPlain executes a query and returns an iterator over rows scanned into T. See Iter.
+// Querier can be either pgx.Tx or pg_types.Pool.
+func IterPlain[T any](ctx context.Context, querier IQuerier, sql string, args pgh.Args) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		rows, err := querier.Query(ctx, sql, args...)
+		if err != nil {
+			yield(zero, pgerr.New("sql select", sql, err))
+			return
+		}
+		defer rows.Close()
+
+		scanner := pgxscan.NewRowScanner(rows)
+		for rows.Next() {
+			var v T
+			if err := scanner.Scan(&v); err != nil {
+				yield(zero, pgerr.New("sql scan", sql, err))
+				return
+			}
+
+			if !yield(v, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(zero, pgerr.New("sql select", sql, err))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package px
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	query := pgh.Builder().Select("id").From("test").Where("name = ?", "a")
+
+	querier := NewMockIQuerier(mc)
+	querier.EXPECT().Query(gomock.Any(), "SELECT id FROM test WHERE name = $1", "a").
+		Return(newInt64RowsMock(mc, []int64{1, 2, 3}), nil)
+
+	var got []int64
+	for v, err := range Iter[int64](ctx, querier, query) {
+		require.NoError(t, err)
+		got = append(got, v)
+	}
+	require.Equal(t, []int64{1, 2, 3}, got)
+
+	// break closes rows
+	rows := NewMockRows(mc)
+	rows.EXPECT().FieldDescriptions().Return([]pgconn.FieldDescription{{Name: "id"}}).AnyTimes() //nolint:exhaustruct // test
+	rows.EXPECT().Next().Return(true)
+	rows.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dst ...any) error {
+		*(dst[0].(*int64)) = 1 //nolint:forcetypeassert // test
+		return nil
+	})
+	rows.EXPECT().Close()
+	querier.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(rows, nil)
+
+	got = nil
+	for v, err := range Iter[int64](ctx, querier, query) {
+		require.NoError(t, err)
+		got = append(got, v)
+		break
+	}
+	require.Equal(t, []int64{1}, got)
+
+	errQuery := errors.New("query error")
+	querier.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errQuery)
+
+	var errs int
+	for _, err := range Iter[int64](ctx, querier, query) {
+		require.ErrorIs(t, err, errQuery)
+		require.ErrorContains(t, err, "SELECT id FROM test")
+		errs++
+	}
+	require.Equal(t, 1, errs)
+}
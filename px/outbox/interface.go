@@ -0,0 +1,22 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+)
+
+//go:generate mockgen -source interface.go -destination interface_mock.go -package outbox
+
+// IPublisher delivers messages to the message broker.
+type IPublisher interface {
+	// Publish publishes a batch of messages. If an error is returned, all messages of the batch are retried.
+	Publish(ctx context.Context, msgs []Message) error
+}
+
+// ISource provides connections to outbox tables polled by Relay.
+type ISource interface {
+	// Run calls f for every outbox table, e.g. for every bucket of a sharded database.
+	// Connections are created without transaction.
+	Run(ctx context.Context, f func(ctx context.Context, con conn.IConnection) error) error
+}
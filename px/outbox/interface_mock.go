@@ -0,0 +1,92 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+//
+// Generated by this command:
+//
+//	mockgen -source interface.go -destination interface_mock.go -package outbox
+//
+
+// Package outbox is a generated GoMock package.
+package outbox
+
+import (
+	context "context"
+	reflect "reflect"
+
+	conn "github.com/n-r-w/pgh/v2/px/db/conn"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIPublisher is a mock of IPublisher interface.
+type MockIPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPublisherMockRecorder
+}
+
+// MockIPublisherMockRecorder is the mock recorder for MockIPublisher.
+type MockIPublisherMockRecorder struct {
+	mock *MockIPublisher
+}
+
+// NewMockIPublisher creates a new mock instance.
+func NewMockIPublisher(ctrl *gomock.Controller) *MockIPublisher {
+	mock := &MockIPublisher{ctrl: ctrl}
+	mock.recorder = &MockIPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPublisher) EXPECT() *MockIPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockIPublisher) Publish(ctx context.Context, msgs []Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, msgs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockIPublisherMockRecorder) Publish(ctx, msgs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockIPublisher)(nil).Publish), ctx, msgs)
+}
+
+// MockISource is a mock of ISource interface.
+type MockISource struct {
+	ctrl     *gomock.Controller
+	recorder *MockISourceMockRecorder
+}
+
+// MockISourceMockRecorder is the mock recorder for MockISource.
+type MockISourceMockRecorder struct {
+	mock *MockISource
+}
+
+// NewMockISource creates a new mock instance.
+func NewMockISource(ctrl *gomock.Controller) *MockISource {
+	mock := &MockISource{ctrl: ctrl}
+	mock.recorder = &MockISourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISource) EXPECT() *MockISourceMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockISource) Run(ctx context.Context, f func(context.Context, conn.IConnection) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockISourceMockRecorder) Run(ctx, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockISource)(nil).Run), ctx, f)
+}
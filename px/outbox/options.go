@@ -0,0 +1,99 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/n-r-w/ctxlog"
+)
+
+// RelayOption option for Relay.
+type RelayOption func(*Relay)
+
+// WithName sets service name.
+func WithName(name string) RelayOption {
+	return func(r *Relay) {
+		r.name = name
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(logger ctxlog.ILogger) RelayOption {
+	return func(r *Relay) {
+		r.logger = logger
+	}
+}
+
+// WithRestartPolicy sets service restart policy on error.
+// Only works when using https://github.com/n-r-w/bootstrap
+func WithRestartPolicy(policy ...backoff.RetryOption) RelayOption {
+	return func(r *Relay) {
+		r.restartPolicy = policy
+	}
+}
+
+// WithBatchSize sets the maximum number of messages claimed from one outbox table per poll. Default is 100.
+func WithBatchSize(size int) RelayOption {
+	return func(r *Relay) {
+		if size > 0 {
+			r.batchSize = size
+		}
+	}
+}
+
+// WithPollInterval sets the interval between polls of the outbox tables. Default is 1 second.
+func WithPollInterval(interval time.Duration) RelayOption {
+	return func(r *Relay) {
+		if interval > 0 {
+			r.pollInterval = interval
+		}
+	}
+}
+
+// WithLease sets how long claimed messages are hidden from other relays.
+// If the publisher does not finish within this time, messages can be delivered twice. Default is 1 minute.
+func WithLease(lease time.Duration) RelayOption {
+	return func(r *Relay) {
+		if lease > 0 {
+			r.lease = lease
+		}
+	}
+}
+
+// WithMaxAttempts sets the number of delivery attempts, after which the message is moved to dead letters.
+// 0 means unlimited attempts. Default is 10.
+func WithMaxAttempts(attempts int) RelayOption {
+	return func(r *Relay) {
+		if attempts >= 0 {
+			r.maxAttempts = attempts
+		}
+	}
+}
+
+// WithRetryDelay sets the delay before the first retry and the maximum delay.
+// The delay is doubled after every failed attempt. Default is 1 second and 5 minutes.
+func WithRetryDelay(initial, maxDelay time.Duration) RelayOption {
+	return func(r *Relay) {
+		if initial > 0 {
+			r.retryDelay = initial
+		}
+		if maxDelay >= r.retryDelay {
+			r.maxRetryDelay = maxDelay
+		}
+	}
+}
+
+// WithDeleteDelivered deletes delivered messages instead of marking them with delivered_at.
+func WithDeleteDelivered() RelayOption {
+	return func(r *Relay) {
+		r.deleteDelivered = true
+	}
+}
+
+// WithDeadLetterFunc sets a function called for messages that exceeded the maximum number of attempts.
+func WithDeadLetterFunc(f func(ctx context.Context, msgs []Message, err error)) RelayOption {
+	return func(r *Relay) {
+		r.deadLetterFunc = f
+	}
+}
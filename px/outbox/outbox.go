@@ -0,0 +1,111 @@
+// Package outbox implements the transactional outbox pattern on top of px/db and txmgr.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+	"github.com/n-r-w/pgh/v2/px/db/sharded/bucket"
+	"github.com/n-r-w/pgh/v2/txmgr"
+)
+
+const (
+	// DefaultTable default name of the outbox table.
+	DefaultTable = "outbox"
+	// BucketTable name of the outbox table for bucket.DB. Each bucket has its own outbox table.
+	BucketTable = bucket.BucketAlias + "." + DefaultTable
+)
+
+// Message outbox message.
+type Message struct {
+	// ID message identifier. Filled by the database.
+	ID int64 `db:"id"`
+	// Topic destination of the message.
+	Topic string `db:"topic"`
+	// Key message key, e.g. for partitioning.
+	Key string `db:"key"`
+	// Payload message body.
+	Payload []byte `db:"payload"`
+	// Headers message headers.
+	Headers map[string]string `db:"headers"`
+	// CreatedAt time of writing to the outbox. Filled by the database.
+	CreatedAt time.Time `db:"created_at"`
+	// Attempts number of delivery attempts including the current one. Filled by the database.
+	Attempts int `db:"attempts"`
+}
+
+// Outbox writes messages to the outbox table.
+type Outbox struct {
+	table string
+}
+
+// New creates a new Outbox for the table. Use BucketTable for bucket.DB.
+func New(table string) *Outbox {
+	return &Outbox{
+		table: table,
+	}
+}
+
+// Table returns the name of the outbox table.
+func (o *Outbox) Table() string {
+	return o.table
+}
+
+// Write writes messages to the outbox table within the current transaction.
+// con must be obtained from the context of a txmgr transaction, otherwise txmgr.ErrNoTransaction is returned.
+// The messages are delivered by Relay only if the transaction is committed.
+func (o *Outbox) Write(ctx context.Context, con conn.IConnection, msgs ...Message) error {
+	if !con.InTransaction() {
+		return fmt.Errorf("outbox write: %w", txmgr.ErrNoTransaction)
+	}
+
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	query := pgh.Builder().Insert(o.table).Columns("topic", "key", "payload", "headers")
+	for _, msg := range msgs {
+		headers := msg.Headers
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		query = query.Values(msg.Topic, msg.Key, msg.Payload, headers)
+	}
+
+	if _, err := px.Exec(ctx, con, query); err != nil {
+		return fmt.Errorf("outbox write: %w", err)
+	}
+
+	return nil
+}
+
+// CreateTableSQL returns SQL for creating the outbox table and its index.
+// For bucket.DB pass it to bucket.DB.InitCluster.
+func (o *Outbox) CreateTableSQL() string {
+	name := o.table
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	id bigserial PRIMARY KEY,
+	topic text NOT NULL,
+	key text NOT NULL DEFAULT '',
+	payload bytea,
+	headers jsonb NOT NULL DEFAULT '{}',
+	created_at timestamptz NOT NULL DEFAULT now(),
+	attempts int NOT NULL DEFAULT 0,
+	next_attempt_at timestamptz NOT NULL DEFAULT now(),
+	locked_until timestamptz,
+	delivered_at timestamptz,
+	dead_at timestamptz,
+	last_error text
+);
+CREATE INDEX IF NOT EXISTS %[2]s_pending_idx ON %[1]s (next_attempt_at, id)
+	WHERE delivered_at IS NULL AND dead_at IS NULL;`, o.table, name)
+}
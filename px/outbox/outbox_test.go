@@ -0,0 +1,130 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/n-r-w/pgh/v2/px/db"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+	"github.com/n-r-w/pgh/v2/txmgr"
+	"github.com/n-r-w/testdock/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOutbox_WriteWithoutTransaction(t *testing.T) {
+	t.Parallel()
+
+	mc := gomock.NewController(t)
+	con := conn.NewMockIConnection(mc)
+	con.EXPECT().InTransaction().Return(false)
+
+	err := New(DefaultTable).Write(context.Background(), con, Message{Topic: "topic"}) //nolint:exhaustruct // test
+	require.ErrorIs(t, err, txmgr.ErrNoTransaction)
+}
+
+func TestRelay(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, informer := testdock.GetPgxPool(t, testdock.DefaultPostgresDSN)
+
+	pxDB := db.New(
+		db.WithName("outbox"),
+		db.WithDSN(informer.DSN()),
+	)
+
+	ctxStart, cancelStart := context.WithTimeout(ctx, 5*time.Second)
+	t.Cleanup(cancelStart)
+	require.NoError(t, pxDB.Start(ctxStart))
+	t.Cleanup(func() {
+		_ = pxDB.Stop(ctx)
+	})
+
+	ob := New(DefaultTable)
+	_, err := px.ExecPlain(ctx, pxDB.Connection(ctx), ob.CreateTableSQL(), nil)
+	require.NoError(t, err)
+
+	tm := txmgr.New(pxDB, pxDB)
+
+	// messages of the rolled back transaction are not delivered
+	errRollback := errors.New("rollback")
+	require.ErrorIs(t, tm.Begin(ctx, func(ctxTr context.Context) error {
+		require.NoError(t, ob.Write(ctxTr, pxDB.Connection(ctxTr),
+			Message{Topic: "orders", Key: "0", Payload: []byte("lost")})) //nolint:exhaustruct // test
+		return errRollback
+	}), errRollback)
+
+	require.NoError(t, tm.Begin(ctx, func(ctxTr context.Context) error {
+		return ob.Write(ctxTr, pxDB.Connection(ctxTr),
+			Message{Topic: "orders", Key: "1", Payload: []byte("first")},                                        //nolint:exhaustruct // test
+			Message{Topic: "orders", Key: "2", Payload: []byte("second"), Headers: map[string]string{"a": "b"}}, //nolint:exhaustruct // test
+		)
+	}))
+
+	mc := gomock.NewController(t)
+	publisher := NewMockIPublisher(mc)
+
+	var deadLetters []Message
+	relay := NewRelay(ob, NewSource(pxDB), publisher,
+		WithMaxAttempts(2),
+		WithRetryDelay(time.Millisecond, time.Millisecond),
+		WithDeadLetterFunc(func(_ context.Context, msgs []Message, _ error) {
+			deadLetters = append(deadLetters, msgs...)
+		}),
+	)
+
+	// first attempt fails, the messages are scheduled for retry
+	publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, msgs []Message) error {
+			require.Len(t, msgs, 2)
+			require.Equal(t, []byte("first"), msgs[0].Payload)
+			require.Equal(t, "b", msgs[1].Headers["a"])
+			require.Equal(t, 1, msgs[0].Attempts)
+			return errors.New("broker unavailable")
+		})
+	n, err := relay.Process(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	time.Sleep(10 * time.Millisecond)
+
+	// second attempt delivers the messages
+	publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, msgs []Message) error {
+			require.Len(t, msgs, 2)
+			require.Equal(t, 2, msgs[0].Attempts)
+			return nil
+		})
+	n, err = relay.Process(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	// nothing left
+	n, err = relay.Process(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// message that always fails is moved to dead letters
+	require.NoError(t, tm.Begin(ctx, func(ctxTr context.Context) error {
+		return ob.Write(ctxTr, pxDB.Connection(ctxTr), Message{Topic: "orders", Key: "3"}) //nolint:exhaustruct // test
+	}))
+
+	publisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(errors.New("invalid message")).Times(2)
+	for range 2 {
+		_, err = relay.Process(ctx)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	require.Len(t, deadLetters, 1)
+	require.Equal(t, "3", deadLetters[0].Key)
+
+	n, err = relay.Process(ctx)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
@@ -0,0 +1,311 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/n-r-w/bootstrap"
+	"github.com/n-r-w/ctxlog"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultPollInterval  = time.Second
+	defaultLease         = time.Minute
+	defaultMaxAttempts   = 10
+	defaultRetryDelay    = time.Second
+	defaultMaxRetryDelay = 5 * time.Minute
+)
+
+// ErrRelayStarted relay is already started.
+var ErrRelayStarted = errors.New("outbox relay already started")
+
+// Relay polls outbox tables and passes messages to IPublisher. Implements bootstrap.IService.
+// Several relays can work with the same tables: messages are claimed with FOR UPDATE SKIP LOCKED
+// and hidden from other relays for the lease time.
+// Delivery is at-least-once, so consumers must be idempotent.
+type Relay struct {
+	outbox    *Outbox
+	source    ISource
+	publisher IPublisher
+
+	name            string
+	logger          ctxlog.ILogger
+	restartPolicy   []backoff.RetryOption
+	batchSize       int
+	pollInterval    time.Duration
+	lease           time.Duration
+	maxAttempts     int
+	retryDelay      time.Duration
+	maxRetryDelay   time.Duration
+	deleteDelivered bool
+	deadLetterFunc  func(ctx context.Context, msgs []Message, err error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ bootstrap.IService = (*Relay)(nil)
+
+// NewRelay creates a new Relay.
+func NewRelay(outbox *Outbox, source ISource, publisher IPublisher, opt ...RelayOption) *Relay {
+	r := &Relay{ //nolint:exhaustruct // default values
+		outbox:        outbox,
+		source:        source,
+		publisher:     publisher,
+		name:          "outbox-relay",
+		logger:        ctxlog.NewStubWrapper(),
+		batchSize:     defaultBatchSize,
+		pollInterval:  defaultPollInterval,
+		lease:         defaultLease,
+		maxAttempts:   defaultMaxAttempts,
+		retryDelay:    defaultRetryDelay,
+		maxRetryDelay: defaultMaxRetryDelay,
+	}
+
+	for _, o := range opt {
+		o(r)
+	}
+
+	return r
+}
+
+// Info returns service information.
+func (r *Relay) Info() bootstrap.Info {
+	return bootstrap.Info{
+		Name:          r.name,
+		RestartPolicy: r.restartPolicy,
+	}
+}
+
+// Start starts polling in background.
+func (r *Relay) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		return ErrRelayStarted
+	}
+
+	r.logger.Debug(ctx, "starting outbox relay", "relay", r.name)
+
+	// ctx of Start is only valid during startup
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.run(runCtx, r.done)
+
+	return nil
+}
+
+// Stop stops polling and waits for the current pass to finish.
+func (r *Relay) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel == nil {
+		return nil
+	}
+
+	r.cancel()
+
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to stop outbox relay %s: %w", r.name, ctx.Err())
+	}
+
+	r.cancel = nil
+	r.done = nil
+
+	r.logger.Debug(ctx, "outbox relay stopped", "relay", r.name)
+
+	return nil
+}
+
+func (r *Relay) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		_, more, err := r.process(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error(ctx, "outbox relay failed", "relay", r.name, "error", err)
+		}
+
+		// if a batch was full, there may be more messages, so we don't wait
+		if err == nil && more {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process makes one pass over all outbox tables of the source and returns the number of processed messages.
+// Publisher errors are not returned: such messages are scheduled for retry or moved to dead letters.
+func (r *Relay) Process(ctx context.Context) (int, error) {
+	processed, _, err := r.process(ctx)
+	return processed, err
+}
+
+func (r *Relay) process(ctx context.Context) (processed int, more bool, err error) {
+	var (
+		total atomic.Int64
+		full  atomic.Bool
+	)
+
+	err = r.source.Run(ctx, func(ctx context.Context, con conn.IConnection) error {
+		n, err := r.processTable(ctx, con)
+		if err != nil {
+			return err
+		}
+
+		total.Add(int64(n))
+		if n >= r.batchSize {
+			full.Store(true)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("outbox relay %s: %w", r.name, err)
+	}
+
+	return int(total.Load()), full.Load(), nil
+}
+
+func (r *Relay) processTable(ctx context.Context, con conn.IConnection) (int, error) {
+	msgs, err := r.claim(ctx, con)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+
+	if errPublish := r.publisher.Publish(ctx, msgs); errPublish != nil {
+		r.logger.Warn(ctx, "failed to publish outbox messages",
+			"relay", r.name, "count", len(msgs), "error", errPublish)
+
+		if err = r.markFailed(ctx, con, msgs, errPublish); err != nil {
+			return 0, err
+		}
+
+		return len(msgs), nil
+	}
+
+	if err = r.markDelivered(ctx, con, msgs); err != nil {
+		return 0, err
+	}
+
+	return len(msgs), nil
+}
+
+// claim selects pending messages and hides them from other relays for the lease time.
+func (r *Relay) claim(ctx context.Context, con conn.IConnection) ([]Message, error) {
+	sql := fmt.Sprintf(`UPDATE %[1]s SET locked_until = now() + make_interval(secs => $1), attempts = attempts + 1
+WHERE id IN (
+	SELECT id FROM %[1]s
+	WHERE delivered_at IS NULL AND dead_at IS NULL AND next_attempt_at <= now()
+		AND (locked_until IS NULL OR locked_until < now())
+	ORDER BY id
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, topic, key, payload, headers, created_at, attempts`, r.outbox.table)
+
+	var msgs []Message
+	if err := px.SelectPlain(ctx, con, sql, &msgs, pgh.Args{r.lease.Seconds(), r.batchSize}); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	// RETURNING does not preserve the order of the subquery
+	slices.SortFunc(msgs, func(a, b Message) int { return cmp.Compare(a.ID, b.ID) })
+
+	return msgs, nil
+}
+
+func (r *Relay) markDelivered(ctx context.Context, con conn.IConnection, msgs []Message) error {
+	var sql string
+	if r.deleteDelivered {
+		sql = fmt.Sprintf(`DELETE FROM %s WHERE id = ANY($1)`, r.outbox.table)
+	} else {
+		sql = fmt.Sprintf(`UPDATE %s SET delivered_at = now(), locked_until = NULL, last_error = NULL
+WHERE id = ANY($1)`, r.outbox.table)
+	}
+
+	if _, err := px.ExecPlain(ctx, con, sql, pgh.Args{messageIDs(msgs)}); err != nil {
+		return fmt.Errorf("failed to mark outbox messages as delivered: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Relay) markFailed(ctx context.Context, con conn.IConnection, msgs []Message, errPublish error) error {
+	sql := fmt.Sprintf(`UPDATE %s SET locked_until = NULL, last_error = $2,
+	dead_at = CASE WHEN $3 > 0 AND attempts >= $3 THEN now() END,
+	next_attempt_at = now() + make_interval(secs => least($4 * power(2, attempts - 1), $5))
+WHERE id = ANY($1)`, r.outbox.table)
+
+	if _, err := px.ExecPlain(ctx, con, sql, pgh.Args{
+		messageIDs(msgs), errPublish.Error(), r.maxAttempts, r.retryDelay.Seconds(), r.maxRetryDelay.Seconds(),
+	}); err != nil {
+		return fmt.Errorf("failed to mark outbox messages as failed: %w", err)
+	}
+
+	if r.maxAttempts == 0 {
+		return nil
+	}
+
+	var dead []Message
+	for _, msg := range msgs {
+		if msg.Attempts >= r.maxAttempts {
+			dead = append(dead, msg)
+		}
+	}
+
+	if len(dead) > 0 {
+		r.logger.Error(ctx, "outbox messages moved to dead letters",
+			"relay", r.name, "count", len(dead), "error", errPublish)
+
+		if r.deadLetterFunc != nil {
+			r.deadLetterFunc(ctx, dead, errPublish)
+		}
+	}
+
+	return nil
+}
+
+func messageIDs(msgs []Message) []int64 {
+	ids := make([]int64, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+
+	return ids
+}
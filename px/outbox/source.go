@@ -0,0 +1,48 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/n-r-w/pgh/v2/px/db"
+	"github.com/n-r-w/pgh/v2/px/db/conn"
+	"github.com/n-r-w/pgh/v2/px/db/sharded/bucket"
+	"github.com/n-r-w/pgh/v2/px/db/sharded/shard"
+)
+
+// connectionSource ISource implementation for a single database.
+type connectionSource struct {
+	getter db.IConnectionGetter
+}
+
+// NewSource creates ISource for a single database, e.g. db.PxDB.
+func NewSource(getter db.IConnectionGetter) ISource {
+	return &connectionSource{
+		getter: getter,
+	}
+}
+
+// Run calls f for the outbox table of the database.
+func (s *connectionSource) Run(ctx context.Context, f func(ctx context.Context, con conn.IConnection) error) error {
+	ctx = db.WithoutTransaction(ctx)
+	return f(ctx, s.getter.Connection(ctx))
+}
+
+// bucketSource ISource implementation for bucket.DB.
+type bucketSource[T any] struct {
+	db *bucket.DB[T]
+}
+
+// NewBucketSource creates ISource for bucket.DB. The outbox table must be BucketTable.
+func NewBucketSource[T any](bucketDB *bucket.DB[T]) ISource {
+	return &bucketSource[T]{
+		db: bucketDB,
+	}
+}
+
+// Run calls f for the outbox table of every bucket.
+func (s *bucketSource[T]) Run(ctx context.Context, f func(ctx context.Context, con conn.IConnection) error) error {
+	return s.db.RunBucketFunc(db.WithoutTransaction(ctx),
+		func(ctx context.Context, _ shard.ShardID, _ bucket.BucketID, con conn.IConnection) error {
+			return f(ctx, con)
+		})
+}
@@ -0,0 +1,203 @@
+package px
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/georgysavva/scany/v2/dbscan"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/n-r-w/pgh/v2/internal/pgerr"
+	sq "github.com/n-r-w/squirrel"
+)
+
+// TotalUnknown value of Page.Total when the total number of rows was not counted.
+const TotalUnknown = -1
+
+// Page page of query results.
+type Page[T any] struct {
+	// Items rows of the page.
+	Items []T
+	// Total number of rows matching the query without pagination. TotalUnknown if not counted.
+	Total int64
+	// HasNext true if there are rows after the page.
+	HasNext bool
+}
+
+// windowScanAPI scans rows with an extra total count column.
+var windowScanAPI = mustNewWindowScanAPI() //nolint:gochecknoglobals // immutable
+
+func mustNewWindowScanAPI() *pgxscan.API {
+	dbscanAPI, err := pgxscan.NewDBScanAPI(dbscan.WithAllowUnknownColumns(true))
+	if err != nil {
+		panic(err)
+	}
+
+	api, err := pgxscan.NewAPI(dbscanAPI)
+	if err != nil {
+		panic(err)
+	}
+
+	return api
+}
+
+// SelectPage selects a page of rows and the total number of rows in one batch.
+// limit and offset are applied to query, 0 means no limit/offset. countQuery must return a single number,
+// for example built by filter.NewCountBuilder. tx can be either pgx.Tx or pg_types.Pool.
+// Outside a transaction the queries are executed in one implicit transaction.
+func SelectPage[T any](ctx context.Context, tx IBatcher, query sq.SelectBuilder, countQuery sq.Sqlizer,
+	limit, offset uint64,
+) (Page[T], error) {
+	page := Page[T]{Items: nil, Total: TotalUnknown, HasNext: false}
+
+	//nolint:exhaustruct // external type, QueuedQueries is managed by Queue method
+	batch := pgx.Batch{}
+	sqls := make([]string, 0, 2) //nolint:mnd // page and count queries
+	for _, q := range []sq.Sqlizer{paginate(query, limit, offset), countQuery} {
+		sql, args, err := sqToSQL(ctx, q)
+		if err != nil {
+			return page, fmt.Errorf("pgx.SelectPage to sql: %w", err)
+		}
+		batch.Queue(sql, args...)
+		sqls = append(sqls, sql)
+	}
+
+	br := tx.SendBatch(ctx, &batch)
+	defer func() { _ = br.Close() }()
+
+	rows, err := br.Query()
+	if err != nil {
+		return page, pgerr.New("pgx.SelectPage query", sqls[0], err)
+	}
+	if err = pgxscan.ScanAll(&page.Items, rows); err != nil {
+		return page, pgerr.New("pgx.SelectPage scan", sqls[0], err)
+	}
+
+	if err = br.QueryRow().Scan(&page.Total); err != nil {
+		return page, pgerr.New("pgx.SelectPage count", sqls[1], err)
+	}
+
+	page.HasNext = limit > 0 && offset+uint64(len(page.Items)) < uint64(page.Total) //nolint:gosec // count is not negative
+
+	return page, nil
+}
+
+// SelectPageWindow selects a page of rows and the total number of rows in one query using COUNT(*) OVER().
+// limit and offset are applied to query, 0 means no limit/offset. T must be a struct.
+// If the page is empty, rows can't be counted by the window function, so a separate COUNT(*) query is executed.
+// Querier can be either pgx.Tx or pg_types.Pool.
+func SelectPageWindow[T any](ctx context.Context, querier IQuerier, query sq.SelectBuilder,
+	limit, offset uint64,
+) (Page[T], error) {
+	page := Page[T]{Items: nil, Total: TotalUnknown, HasNext: false}
+
+	const totalColumn = "pgh_total_count"
+
+	sql, args, err := sqToSQL(ctx, paginate(query.Column("COUNT(*) OVER() AS "+totalColumn), limit, offset))
+	if err != nil {
+		return page, fmt.Errorf("pgx.SelectPageWindow to sql: %w", err)
+	}
+
+	rows, err := querier.Query(ctx, sql, args...)
+	if err != nil {
+		return page, pgerr.New("pgx.SelectPageWindow", sql, err)
+	}
+	defer rows.Close()
+
+	totalIndex := -1
+	for i, field := range rows.FieldDescriptions() {
+		if field.Name == totalColumn {
+			totalIndex = i
+		}
+	}
+
+	scanner := windowScanAPI.NewRowScanner(rows)
+	for rows.Next() {
+		var item T
+		if err = scanner.Scan(&item); err != nil {
+			return page, pgerr.New("pgx.SelectPageWindow scan", sql, err)
+		}
+		page.Items = append(page.Items, item)
+
+		if page.Total == TotalUnknown && totalIndex >= 0 {
+			values, errValues := rows.Values()
+			if errValues != nil {
+				return page, pgerr.New("pgx.SelectPageWindow scan", sql, errValues)
+			}
+			if total, ok := values[totalIndex].(int64); ok {
+				page.Total = total
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return page, pgerr.New("pgx.SelectPageWindow", sql, err)
+	}
+	rows.Close()
+
+	if len(page.Items) == 0 {
+		if offset == 0 {
+			page.Total = 0
+			return page, nil
+		}
+
+		if page.Total, err = countRows(ctx, querier, query); err != nil {
+			return page, fmt.Errorf("pgx.SelectPageWindow: %w", err)
+		}
+	}
+
+	page.HasNext = limit > 0 && offset+uint64(len(page.Items)) < uint64(page.Total) //nolint:gosec // count is not negative
+
+	return page, nil
+}
+
+// SelectPageHasNext selects a page of rows without counting. To determine if there is a next page,
+// limit+1 rows are selected and the extra row is discarded. Total is TotalUnknown.
+// Querier can be either pgx.Tx or pg_types.Pool.
+func SelectPageHasNext[T any](ctx context.Context, querier IQuerier, query sq.SelectBuilder,
+	limit, offset uint64,
+) (Page[T], error) {
+	page := Page[T]{Items: nil, Total: TotalUnknown, HasNext: false}
+
+	fetchLimit := limit
+	if limit > 0 {
+		fetchLimit++
+	}
+
+	if err := Select(ctx, querier, paginate(query, fetchLimit, offset), &page.Items); err != nil {
+		return page, err
+	}
+
+	if limit > 0 && uint64(len(page.Items)) > limit {
+		page.Items = page.Items[:limit]
+		page.HasNext = true
+	}
+
+	return page, nil
+}
+
+// paginate replaces LIMIT and OFFSET of the query.
+func paginate(query sq.SelectBuilder, limit, offset uint64) sq.SelectBuilder {
+	query = query.RemoveLimit().RemoveOffset()
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	return query
+}
+
+// countRows counts rows of the query without pagination.
+func countRows(ctx context.Context, querier IQuerier, query sq.SelectBuilder) (int64, error) {
+	countQuery := sq.Select("COUNT(*)").
+		FromSelect(query.RemoveLimit().RemoveOffset(), "pgh_count").
+		PlaceholderFormat(sq.Dollar)
+
+	var total int64
+	if err := SelectOne(ctx, querier, countQuery, &total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
@@ -0,0 +1,154 @@
+package px
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// newInt64RowsMock returns rows with a single int64 column.
+func newInt64RowsMock(mc *gomock.Controller, values []int64) *MockRows {
+	rows := NewMockRows(mc)
+
+	idx := -1
+	rows.EXPECT().FieldDescriptions().Return([]pgconn.FieldDescription{{Name: "id"}}).AnyTimes() //nolint:exhaustruct // test
+	rows.EXPECT().Next().DoAndReturn(func() bool {
+		idx++
+		return idx < len(values)
+	}).AnyTimes()
+	rows.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dst ...any) error {
+		*(dst[0].(*int64)) = values[idx] //nolint:forcetypeassert // test
+		return nil
+	}).AnyTimes()
+	rows.EXPECT().Err().Return(nil).AnyTimes()
+	rows.EXPECT().Close().AnyTimes()
+
+	return rows
+}
+
+func TestSelectPage(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	query := pgh.Builder().Select("id").From("test").Where("name = ?", "a").OrderBy("id").Limit(1000)
+	countQuery := pgh.Builder().Select("COUNT(*)").From("test").Where("name = ?", "a")
+
+	batchMock := NewMockIBatcher(mc)
+	batchMock.EXPECT().SendBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, batch *pgx.Batch) pgx.BatchResults {
+			require.Equal(t, 2, batch.Len())
+			require.Equal(t, "SELECT id FROM test WHERE name = $1 ORDER BY id LIMIT 2 OFFSET 2",
+				batch.QueuedQueries[0].SQL)
+			require.Equal(t, "SELECT COUNT(*) FROM test WHERE name = $1", batch.QueuedQueries[1].SQL)
+
+			countRow := NewMockRow(mc)
+			countRow.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dst ...any) error {
+				*(dst[0].(*int64)) = 5 //nolint:forcetypeassert // test
+				return nil
+			})
+
+			batchResultMock := NewMockBatchResults(mc)
+			batchResultMock.EXPECT().Query().Return(newInt64RowsMock(mc, []int64{3, 4}), nil)
+			batchResultMock.EXPECT().QueryRow().Return(countRow)
+			batchResultMock.EXPECT().Close().Return(nil)
+
+			return batchResultMock
+		})
+
+	page, err := SelectPage[int64](ctx, batchMock, query, countQuery, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, Page[int64]{Items: []int64{3, 4}, Total: 5, HasNext: true}, page)
+}
+
+func TestSelectPageErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	query := pgh.Builder().Select("id").From("test").OrderBy("id")
+	countQuery := pgh.Builder().Select("COUNT(*)").From("test")
+	errQuery := errors.New("query failed")
+
+	// count error of SelectPage
+	batchMock := NewMockIBatcher(mc)
+	batchMock.EXPECT().SendBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ *pgx.Batch) pgx.BatchResults {
+			countRow := NewMockRow(mc)
+			countRow.EXPECT().Scan(gomock.Any()).Return(errQuery)
+
+			batchResultMock := NewMockBatchResults(mc)
+			batchResultMock.EXPECT().Query().Return(newInt64RowsMock(mc, []int64{1}), nil)
+			batchResultMock.EXPECT().QueryRow().Return(countRow)
+			batchResultMock.EXPECT().Close().Return(nil)
+
+			return batchResultMock
+		})
+
+	_, err := SelectPage[int64](ctx, batchMock, query, countQuery, 2, 0)
+	require.ErrorIs(t, err, errQuery)
+	pgErr, ok := AsError(err)
+	require.True(t, ok)
+	require.Equal(t, "SELECT COUNT(*) FROM test", pgErr.SQL)
+
+	// query error of SelectPageWindow
+	querierMock := NewMockIQuerier(mc)
+	querierMock.EXPECT().Query(gomock.Any(), gomock.Any()).Return(nil, errQuery)
+
+	_, err = SelectPageWindow[int64](ctx, querierMock, query, 2, 0)
+	require.ErrorIs(t, err, errQuery)
+	pgErr, ok = AsError(err)
+	require.True(t, ok)
+	require.Equal(t, "SELECT id, COUNT(*) OVER() AS pgh_total_count FROM test ORDER BY id LIMIT 2", pgErr.SQL)
+}
+
+func TestSelectPageHasNext(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	query := pgh.Builder().Select("id").From("test").OrderBy("id")
+
+	tests := []struct {
+		name     string
+		selected []int64
+		want     Page[int64]
+	}{
+		{
+			name:     "has next",
+			selected: []int64{1, 2, 3},
+			want:     Page[int64]{Items: []int64{1, 2}, Total: TotalUnknown, HasNext: true},
+		},
+		{
+			name:     "last page",
+			selected: []int64{1, 2},
+			want:     Page[int64]{Items: []int64{1, 2}, Total: TotalUnknown, HasNext: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mc := gomock.NewController(t)
+			defer mc.Finish()
+
+			querier := NewMockIQuerier(mc)
+			querier.EXPECT().Query(gomock.Any(), "SELECT id FROM test ORDER BY id LIMIT 3").
+				Return(newInt64RowsMock(mc, tt.selected), nil)
+
+			page, err := SelectPageHasNext[int64](ctx, querier, query, 2, 0)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, page)
+		})
+	}
+}
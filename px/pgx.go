@@ -10,6 +10,7 @@ import (
 	pgx "github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/internal/pgerr"
 )
 
 // ExecPlain executes a modification query. Querier can be either pgx.Tx or pg_types.Pool.
@@ -20,7 +21,7 @@ func ExecPlain(ctx context.Context, querier IQuerier, sql string, args pgh.Args)
 	)
 
 	if tag, err = querier.Exec(ctx, sql, args...); err != nil {
-		return tag, fmt.Errorf("sql exec: %w [%s]", err, pgh.TruncSQL(sql))
+		return tag, pgerr.New("sql exec", sql, err)
 	}
 
 	return tag, nil
@@ -29,7 +30,7 @@ func ExecPlain(ctx context.Context, querier IQuerier, sql string, args pgh.Args)
 // SelectPlain executes a query. Querier can be either pgx.Tx or pg_types.Pool.
 func SelectPlain[T any](ctx context.Context, querier IQuerier, sql string, dst *[]T, args pgh.Args) error {
 	if err := pgxscan.Select(ctx, querier, dst, sql, args...); err != nil {
-		return fmt.Errorf("sql select: %w [%s]", err, pgh.TruncSQL(sql))
+		return pgerr.New("sql select", sql, err)
 	}
 
 	return nil
@@ -42,7 +43,7 @@ func SelectFuncPlain(ctx context.Context, querier IQuerier, sql string, args pgh
 ) error {
 	rows, err := querier.Query(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("sql select: %w [%s]", err, pgh.TruncSQL(sql))
+		return pgerr.New("sql select", sql, err)
 	}
 	defer rows.Close()
 
@@ -53,7 +54,7 @@ func SelectFuncPlain(ctx context.Context, querier IQuerier, sql string, args pgh
 	}
 
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("sql select: %w [%s]", err, pgh.TruncSQL(sql))
+		return pgerr.New("sql select", sql, err)
 	}
 
 	return nil
@@ -68,7 +69,7 @@ func SelectOnePlain[T any](ctx context.Context, querier IQuerier, sql string, ds
 			return pgx.ErrNoRows
 		}
 
-		return fmt.Errorf("sql select: %w [%s]", err, pgh.TruncSQL(sql))
+		return pgerr.New("sql select", sql, err)
 	}
 
 	return nil
@@ -164,7 +165,7 @@ func SendBatch(ctx context.Context, tx IBatcher, batch *pgx.Batch) (rowsAffected
 	for i := range batch.Len() {
 		tag, err := br.Exec()
 		if err != nil {
-			return 0, fmt.Errorf("pgx.SendBatch exec at index %d: %w", i, err)
+			return 0, pgerr.New(fmt.Sprintf("pgx.SendBatch exec at index %d", i), batch.QueuedQueries[i].SQL, err)
 		}
 		rowsAffected += tag.RowsAffected()
 	}
@@ -184,12 +185,12 @@ func SendBatchQuery[T any](ctx context.Context, tx IBatcher, batch *pgx.Batch, d
 	for i := range batch.Len() {
 		rows, err := br.Query()
 		if err != nil {
-			return fmt.Errorf("pgx.SendBatchQuery query at index %d: %w", i, err)
+			return pgerr.New(fmt.Sprintf("pgx.SendBatchQuery query at index %d", i), batch.QueuedQueries[i].SQL, err)
 		}
 
 		var dstBatch []T
 		if err := pgxscan.ScanAll(&dstBatch, rows); err != nil {
-			return fmt.Errorf("pgx.SendBatchQuery scan at index %d: %w", i, err)
+			return pgerr.New(fmt.Sprintf("pgx.SendBatchQuery scan at index %d", i), batch.QueuedQueries[i].SQL, err)
 		}
 
 		*dst = append(*dst, dstBatch...)
@@ -236,7 +237,7 @@ func InsertValuesPlain(ctx context.Context, querier IQuerier, sql string, values
 	targetSQL := sqlBuilder.String()
 
 	if _, err := querier.Exec(ctx, targetSQL, args...); err != nil {
-		return fmt.Errorf("pgx.InsertValues: %w [%s]", err, pgh.TruncSQL(targetSQL))
+		return pgerr.New("pgx.InsertValues", targetSQL, err)
 	}
 
 	return nil
@@ -0,0 +1,235 @@
+// Package repository provides a generic CRUD repository on top of px and filter.
+// Table columns and the primary key are derived from `db` struct tags:
+//
+//	type User struct {
+//		ID        int64     `db:"id,pk,readonly"`
+//		Name      string    `db:"name"`
+//		Email     string    `db:"email,omitempty"`
+//		CreatedAt time.Time `db:"created_at,readonly"`
+//	}
+//
+// Options: pk - part of the primary key, readonly - generated by the database and never written,
+// omitempty - not inserted if zero, so that the database default is used.
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/filter"
+	"github.com/n-r-w/pgh/v2/internal/structmap"
+	"github.com/n-r-w/pgh/v2/px"
+	sq "github.com/n-r-w/squirrel"
+)
+
+var (
+	// ErrNoPrimaryKey the struct has no fields with the pk option.
+	ErrNoPrimaryKey = px.ErrNoPrimaryKey
+	// ErrInvalidKey the number of key values doesn't match the primary key.
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrNoTableName table name is not provided.
+	ErrNoTableName = errors.New("no table name")
+	// ErrNothingToUpdate the struct has no writable fields except the primary key.
+	ErrNothingToUpdate = px.ErrNothingToUpdate
+)
+
+// ITableNamer can be implemented by T to provide the table name.
+type ITableNamer interface {
+	TableName() string
+}
+
+// Repository CRUD operations for table rows mapped to T.
+type Repository[T any] struct {
+	table   string
+	columns []string
+	pk      []structmap.Field
+}
+
+// New creates a new Repository. If table is empty, T must implement ITableNamer.
+func New[T any](table string) (*Repository[T], error) {
+	meta, err := structmap.Of[T]()
+	if err != nil {
+		return nil, fmt.Errorf("repository: %w", err)
+	}
+
+	if table == "" {
+		var v T
+		if namer, ok := any(v).(ITableNamer); ok {
+			table = namer.TableName()
+		} else if namer, ok := any(&v).(ITableNamer); ok {
+			table = namer.TableName()
+		}
+	}
+	if table == "" {
+		return nil, fmt.Errorf("repository %T: %w", *new(T), ErrNoTableName)
+	}
+
+	pk := meta.PK()
+	if len(pk) == 0 {
+		return nil, fmt.Errorf("repository %s: %w", table, ErrNoPrimaryKey)
+	}
+
+	return &Repository[T]{
+		table:   table,
+		columns: meta.Columns(),
+		pk:      pk,
+	}, nil
+}
+
+// Table returns the table name.
+func (r *Repository[T]) Table() string {
+	return r.table
+}
+
+// Columns returns all columns of the table.
+func (r *Repository[T]) Columns() []string {
+	return r.columns
+}
+
+// Insert inserts a row and returns it with the values generated by the database.
+func (r *Repository[T]) Insert(ctx context.Context, querier px.IQuerier, v T) (T, error) {
+	columns, values, err := px.StructValues([]T{v})
+	if err != nil {
+		return *new(T), fmt.Errorf("insert into %s: %w", r.table, err)
+	}
+
+	returning := "RETURNING " + strings.Join(r.columns, ", ")
+
+	var res T
+	if len(columns) == 0 {
+		err = px.SelectOnePlain(ctx, querier, "INSERT INTO "+r.table+" DEFAULT VALUES "+returning, &res, nil)
+	} else {
+		err = px.SelectOne(ctx, querier, pgh.Builder().Insert(r.table).
+			Columns(columns...).
+			Values(values[0]...).
+			Suffix(returning), &res)
+	}
+	if err != nil {
+		return res, fmt.Errorf("insert into %s: %w", r.table, err)
+	}
+
+	return res, nil
+}
+
+// GetByID returns a row by primary key values in the order of the pk fields.
+// Returns pgx.ErrNoRows if the row is not found.
+func (r *Repository[T]) GetByID(ctx context.Context, querier px.IQuerier, key ...any) (T, error) {
+	var res T
+
+	where, err := r.keyCondition(key)
+	if err != nil {
+		return res, err
+	}
+
+	query := pgh.Builder().Select(r.columns...).From(r.table).Where(where)
+	if err = px.SelectOne(ctx, querier, query, &res); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return res, err
+		}
+		return res, fmt.Errorf("get from %s: %w", r.table, err)
+	}
+
+	return res, nil
+}
+
+// Update updates all writable columns of the row by primary key and returns the updated row.
+// Returns pgx.ErrNoRows if the row is not found.
+func (r *Repository[T]) Update(ctx context.Context, querier px.IQuerier, v T) (T, error) {
+	var res T
+
+	query, err := px.UpdateStructBuilder(pgh.Builder().Update(r.table), v)
+	if err != nil {
+		return res, fmt.Errorf("update %s: %w", r.table, err)
+	}
+	query = query.Suffix("RETURNING " + strings.Join(r.columns, ", "))
+
+	if err = px.SelectOne(ctx, querier, query, &res); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return res, err
+		}
+		return res, fmt.Errorf("update %s: %w", r.table, err)
+	}
+
+	return res, nil
+}
+
+// Delete deletes a row by primary key values in the order of the pk fields.
+// Returns pgx.ErrNoRows if the row is not found.
+func (r *Repository[T]) Delete(ctx context.Context, querier px.IQuerier, key ...any) error {
+	where, err := r.keyCondition(key)
+	if err != nil {
+		return err
+	}
+
+	tag, err := px.Exec(ctx, querier, pgh.Builder().Delete(r.table).Where(where))
+	if err != nil {
+		return fmt.Errorf("delete from %s: %w", r.table, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// List returns rows matching filter options. If the primary key is not "id",
+// it is used as the default sort and the key of the service and cursor paginators.
+func (r *Repository[T]) List(ctx context.Context, querier px.IQuerier, opts ...filter.Option) ([]T, error) {
+	builder, err := filter.NewSelectBuilder(r.filterOptions(opts)...)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", r.table, err)
+	}
+
+	var res []T
+	if err = px.Select(ctx, querier, builder.Columns(r.columns...).From(r.table), &res); err != nil {
+		return nil, fmt.Errorf("list %s: %w", r.table, err)
+	}
+
+	return res, nil
+}
+
+// Count returns the number of rows matching filter options. Sorting and pagination are ignored.
+func (r *Repository[T]) Count(ctx context.Context, querier px.IQuerier, opts ...filter.Option) (int64, error) {
+	builder, err := filter.NewCountBuilder(r.filterOptions(opts)...)
+	if err != nil {
+		return 0, fmt.Errorf("count %s: %w", r.table, err)
+	}
+
+	var count int64
+	if err = px.SelectOne(ctx, querier, builder.From(r.table), &count); err != nil {
+		return 0, fmt.Errorf("count %s: %w", r.table, err)
+	}
+
+	return count, nil
+}
+
+// filterOptions adds the primary key before user options, so that they can override it.
+func (r *Repository[T]) filterOptions(opts []filter.Option) []filter.Option {
+	pk := structmap.ColumnsOf(r.pk)
+
+	pkField := pk[0]
+	if len(pk) > 1 {
+		pkField = "(" + strings.Join(pk, ", ") + ")"
+	}
+
+	return append([]filter.Option{filter.WithPKField(pkField)}, opts...)
+}
+
+// keyCondition returns condition for primary key values.
+func (r *Repository[T]) keyCondition(key []any) (sq.Eq, error) {
+	if len(key) != len(r.pk) {
+		return nil, fmt.Errorf("%s: %w: expected %d values, got %d", r.table, ErrInvalidKey, len(r.pk), len(key))
+	}
+
+	where := sq.Eq{}
+	for i, f := range r.pk {
+		where[f.Column] = key[i]
+	}
+
+	return where, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2/filter"
+	"github.com/n-r-w/pgh/v2/px"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type member struct {
+	OrgID int64  `db:"org_id,pk"`
+	ID    int64  `db:"id,pk,readonly"`
+	Name  string `db:"name"`
+	Role  string `db:"role,omitempty"`
+	Seq   int64  `db:"seq,readonly"`
+}
+
+func (member) TableName() string {
+	return "members"
+}
+
+// newRowsMock returns rows with the given columns and values.
+func newRowsMock(mc *gomock.Controller, columns []string, values ...[]any) *px.MockRows {
+	rows := px.NewMockRows(mc)
+
+	fields := make([]pgconn.FieldDescription, 0, len(columns))
+	for _, c := range columns {
+		fields = append(fields, pgconn.FieldDescription{Name: c}) //nolint:exhaustruct // test
+	}
+
+	idx := -1
+	rows.EXPECT().FieldDescriptions().Return(fields).AnyTimes()
+	rows.EXPECT().Next().DoAndReturn(func() bool {
+		idx++
+		return idx < len(values)
+	}).AnyTimes()
+	rows.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dst ...any) error {
+		for i, d := range dst {
+			reflect.ValueOf(d).Elem().Set(reflect.ValueOf(values[idx][i]))
+		}
+		return nil
+	}).AnyTimes()
+	rows.EXPECT().Err().Return(nil).AnyTimes()
+	rows.EXPECT().Close().AnyTimes()
+
+	return rows
+}
+
+var memberColumns = []string{"org_id", "id", "name", "role", "seq"} //nolint:gochecknoglobals // test
+
+func TestRepository(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	repo, err := New[member]("")
+	require.NoError(t, err)
+	require.Equal(t, "members", repo.Table())
+	require.Equal(t, memberColumns, repo.Columns())
+
+	stored := member{OrgID: 1, ID: 2, Name: "bob", Role: "user", Seq: 10}
+	storedRow := []any{int64(1), int64(2), "bob", "user", int64(10)}
+
+	querier := px.NewMockIQuerier(mc)
+
+	t.Run("Insert", func(t *testing.T) {
+		// readonly and empty omitempty columns are not inserted
+		querier.EXPECT().Query(gomock.Any(),
+			"INSERT INTO members (org_id,name) VALUES ($1,$2) RETURNING org_id, id, name, role, seq",
+			int64(1), "bob").
+			Return(newRowsMock(mc, memberColumns, storedRow), nil)
+
+		res, err := repo.Insert(ctx, querier, member{OrgID: 1, ID: 0, Name: "bob", Role: "", Seq: 0})
+		require.NoError(t, err)
+		require.Equal(t, stored, res)
+	})
+
+	t.Run("GetByID", func(t *testing.T) {
+		querier.EXPECT().Query(gomock.Any(),
+			"SELECT org_id, id, name, role, seq FROM members WHERE id = $1 AND org_id = $2",
+			2, 1).
+			Return(newRowsMock(mc, memberColumns, storedRow), nil)
+
+		res, err := repo.GetByID(ctx, querier, 1, 2)
+		require.NoError(t, err)
+		require.Equal(t, stored, res)
+
+		_, err = repo.GetByID(ctx, querier, 1)
+		require.ErrorIs(t, err, ErrInvalidKey)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		querier.EXPECT().Query(gomock.Any(),
+			"UPDATE members SET name = $1, role = $2 WHERE id = $3 AND org_id = $4 "+
+				"RETURNING org_id, id, name, role, seq",
+			"bob", "user", int64(2), int64(1)).
+			Return(newRowsMock(mc, memberColumns), nil)
+
+		_, err := repo.Update(ctx, querier, stored)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		querier.EXPECT().Exec(gomock.Any(), "DELETE FROM members WHERE id = $1 AND org_id = $2", 2, 1).
+			Return(pgconn.NewCommandTag("DELETE 1"), nil)
+
+		require.NoError(t, repo.Delete(ctx, querier, 1, 2))
+	})
+
+	t.Run("List", func(t *testing.T) {
+		querier.EXPECT().Query(gomock.Any(),
+			"SELECT org_id, id, name, role, seq FROM members WHERE name IN ($1) ORDER BY (org_id, id) ASC LIMIT 10",
+			"bob").
+			Return(newRowsMock(mc, memberColumns, storedRow), nil)
+
+		res, err := repo.List(ctx, querier,
+			filter.WithIn("name", []string{"bob"}),
+			filter.WithPaginator(*filter.NewFrontPaginator(10, 1)),
+		)
+		require.NoError(t, err)
+		require.Equal(t, []member{stored}, res)
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		querier.EXPECT().Query(gomock.Any(), "SELECT COUNT(*) FROM members WHERE name IN ($1)", "bob").
+			Return(newRowsMock(mc, []string{"count"}, []any{int64(3)}), nil)
+
+		count, err := repo.Count(ctx, querier, filter.WithIn("name", []string{"bob"}))
+		require.NoError(t, err)
+		require.Equal(t, int64(3), count)
+	})
+}
+
+func TestNew_Errors(t *testing.T) {
+	t.Parallel()
+
+	type noPK struct {
+		Name string `db:"name"`
+	}
+
+	_, err := New[noPK]("items")
+	require.ErrorIs(t, err, ErrNoPrimaryKey)
+
+	_, err = New[noPK]("")
+	require.ErrorIs(t, err, ErrNoTableName)
+}
@@ -0,0 +1,183 @@
+package px
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/internal/structmap"
+	sq "github.com/n-r-w/squirrel"
+)
+
+// Helpers for mapping structs to columns using `db` tags, the same tags scany uses for scanning.
+// Tag options: pk - part of the primary key, readonly - never inserted or updated,
+// omitempty - DEFAULT is inserted instead of the zero value.
+
+var (
+	// ErrNoPrimaryKey the struct has no fields with the pk tag option.
+	ErrNoPrimaryKey = errors.New("no primary key")
+	// ErrNothingToUpdate the struct has no writable fields except the primary key.
+	ErrNothingToUpdate = errors.New("nothing to update")
+	// ErrNothingToInsert the struct has no writable fields or all of them are omitempty and zero.
+	ErrNothingToInsert = errors.New("nothing to insert")
+)
+
+// sqlDefault DEFAULT value for omitempty columns.
+var sqlDefault = sq.Expr("DEFAULT") //nolint:gochecknoglobals // immutable
+
+// StructValues returns columns and rows of values for inserting structs. Columns are in the order of
+// struct fields, so values always match them. readonly fields are skipped. omitempty fields are skipped if
+// they are zero in all rows, otherwise DEFAULT is inserted for zero values.
+// Returns ErrNothingToInsert if rows are not empty, but there are no columns to insert.
+// T can be a pointer to a struct, nil rows are not allowed.
+func StructValues[T any](rows []T) ([]string, []pgh.Args, error) {
+	meta, err := structmap.Of[T]()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fields := meta.Writable()
+
+	rowValues := make([]reflect.Value, 0, len(rows))
+	for i, row := range rows {
+		v, err := structmap.Indirect(reflect.ValueOf(row))
+		if err != nil {
+			return nil, nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		rowValues = append(rowValues, v)
+	}
+
+	// omitempty fields that are zero in all rows are skipped
+	used := make([]bool, len(fields))
+	for i, f := range fields {
+		used[i] = !f.OmitEmpty
+	}
+	for _, v := range rowValues {
+		for i, f := range fields {
+			if !used[i] && !isZero(f.Value(v)) {
+				used[i] = true
+			}
+		}
+	}
+
+	columns := make([]string, 0, len(fields))
+	for i, f := range fields {
+		if used[i] {
+			columns = append(columns, f.Column)
+		}
+	}
+	if len(columns) == 0 && len(rows) > 0 {
+		return nil, nil, fmt.Errorf("%T: %w", rows[0], ErrNothingToInsert)
+	}
+
+	values := make([]pgh.Args, 0, len(rows))
+	for _, v := range rowValues {
+		args := make(pgh.Args, 0, len(columns))
+		for i, f := range fields {
+			if !used[i] {
+				continue
+			}
+
+			value := f.Value(v)
+			if f.OmitEmpty && isZero(value) {
+				value = sqlDefault
+			}
+			args = append(args, value)
+		}
+		values = append(values, args)
+	}
+
+	return columns, values, nil
+}
+
+// InsertStructs inserts structs using InsertSplit. Columns are taken from the `db` tags of T,
+// base must contain only the table and optional suffix, e.g. ON CONFLICT.
+// tx can be either pgx.Tx or pg_types.Pool.
+func InsertStructs[T any](
+	ctx context.Context,
+	tx IBatcher,
+	base sq.InsertBuilder,
+	rows []T,
+	splitSize int,
+) (rowsAffected int64, err error) {
+	columns, values, err := StructValues(rows)
+	if err != nil {
+		return 0, fmt.Errorf("InsertStructs: %w", err)
+	}
+
+	return InsertSplit(ctx, tx, base.Columns(columns...), values, splitSize)
+}
+
+// InsertStructsQuery inserts structs using InsertSplitQuery. Columns are taken from the `db` tags of T,
+// base must contain only the table and the RETURNING suffix. Returned rows are scanned into dst.
+// tx can be either pgx.Tx or pg_types.Pool.
+func InsertStructsQuery[T, R any](
+	ctx context.Context,
+	tx IBatcher,
+	base sq.InsertBuilder,
+	rows []T,
+	splitSize int,
+	dst *[]R,
+) error {
+	columns, values, err := StructValues(rows)
+	if err != nil {
+		return fmt.Errorf("InsertStructsQuery: %w", err)
+	}
+
+	return InsertSplitQuery(ctx, tx, base.Columns(columns...), values, splitSize, dst)
+}
+
+// UpdateStructBuilder adds SET for all writable columns except the primary key and WHERE by the primary key
+// to base, which must contain the table and can contain additional conditions and suffix.
+// T can be a pointer to a struct, nil is not allowed.
+func UpdateStructBuilder[T any](base sq.UpdateBuilder, v T) (sq.UpdateBuilder, error) {
+	meta, err := structmap.Of[T]()
+	if err != nil {
+		return base, err
+	}
+
+	pk := meta.PK()
+	if len(pk) == 0 {
+		return base, fmt.Errorf("%T: %w", v, ErrNoPrimaryKey)
+	}
+
+	value, err := structmap.Indirect(reflect.ValueOf(v))
+	if err != nil {
+		return base, err
+	}
+
+	set := sq.Eq{}
+	for _, f := range meta.Writable() {
+		if !f.PK {
+			set[f.Column] = f.Value(value)
+		}
+	}
+	if len(set) == 0 {
+		return base, fmt.Errorf("%T: %w", v, ErrNothingToUpdate)
+	}
+
+	where := sq.Eq{}
+	for _, f := range pk {
+		where[f.Column] = f.Value(value)
+	}
+
+	return base.SetMap(set).Where(where), nil
+}
+
+// UpdateStruct updates all writable columns except the primary key of the row found by the primary key.
+// base must contain the table and can contain additional conditions. Querier can be either pgx.Tx or pg_types.Pool.
+func UpdateStruct[T any](ctx context.Context, querier IQuerier, base sq.UpdateBuilder, v T) (pgconn.CommandTag, error) {
+	query, err := UpdateStructBuilder(base, v)
+	if err != nil {
+		return pgconn.CommandTag{}, fmt.Errorf("UpdateStruct: %w", err)
+	}
+
+	return Exec(ctx, querier, query)
+}
+
+func isZero(v any) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
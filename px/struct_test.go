@@ -0,0 +1,137 @@
+package px
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/n-r-w/pgh/v2/internal/structmap"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type structItem struct {
+	ID      int64  `db:"id,pk,readonly"`
+	Name    string `db:"name"`
+	Status  string `db:"status,omitempty"`
+	Comment string `db:"comment,omitempty"`
+	Ignored string `db:"-"`
+}
+
+func TestStructValues(t *testing.T) {
+	t.Parallel()
+
+	columns, values, err := StructValues([]structItem{
+		{ID: 1, Name: "a", Status: "new", Comment: "", Ignored: "x"},
+		{ID: 2, Name: "b", Status: "", Comment: "", Ignored: "y"},
+	})
+	require.NoError(t, err)
+
+	// readonly id is skipped, comment is empty in all rows, empty status is replaced by DEFAULT
+	require.Equal(t, []string{"name", "status"}, columns)
+	require.Equal(t, []pgh.Args{{"a", "new"}, {"b", sqlDefault}}, values)
+
+	// pointers to structs
+	columns, values, err = StructValues([]*structItem{
+		{ID: 1, Name: "a", Status: "", Comment: "c", Ignored: ""},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"name", "comment"}, columns)
+	require.Equal(t, []pgh.Args{{"a", "c"}}, values)
+
+	_, _, err = StructValues([]*structItem{nil})
+	require.ErrorIs(t, err, structmap.ErrNilPointer)
+
+	// no columns to insert
+	type optionalItem struct {
+		ID      int64  `db:"id,pk,readonly"`
+		Comment string `db:"comment,omitempty"`
+	}
+	_, _, err = StructValues([]optionalItem{{ID: 1, Comment: ""}})
+	require.ErrorIs(t, err, ErrNothingToInsert)
+
+	type readonlyItem struct {
+		ID int64 `db:"id,pk,readonly"`
+	}
+	_, _, err = StructValues([]readonlyItem{{ID: 1}})
+	require.ErrorIs(t, err, ErrNothingToInsert)
+
+	columns, values, err = StructValues([]readonlyItem{})
+	require.NoError(t, err)
+	require.Empty(t, columns)
+	require.Empty(t, values)
+}
+
+func TestInsertStructs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	batchMock := NewMockIBatcher(mc)
+	batchMock.EXPECT().SendBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, batch *pgx.Batch) pgx.BatchResults {
+			require.Equal(t, 2, batch.Len())
+			require.Equal(t, "INSERT INTO items (name,status) VALUES ($1,$2),($3,DEFAULT) ON CONFLICT DO NOTHING",
+				batch.QueuedQueries[0].SQL)
+			require.Equal(t, []any{"a", "new", "b"}, batch.QueuedQueries[0].Arguments)
+			require.Equal(t, "INSERT INTO items (name,status) VALUES ($1,$2) ON CONFLICT DO NOTHING",
+				batch.QueuedQueries[1].SQL)
+
+			batchResultMock := NewMockBatchResults(mc)
+			batchResultMock.EXPECT().Exec().Return(pgconn.NewCommandTag("INSERT 0 2"), nil)
+			batchResultMock.EXPECT().Exec().Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+			batchResultMock.EXPECT().Close().Return(nil)
+
+			return batchResultMock
+		})
+
+	ra, err := InsertStructs(ctx, batchMock,
+		pgh.Builder().Insert("items").Suffix("ON CONFLICT DO NOTHING"),
+		[]structItem{
+			{ID: 0, Name: "a", Status: "new", Comment: "", Ignored: ""},
+			{ID: 0, Name: "b", Status: "", Comment: "", Ignored: ""},
+			{ID: 0, Name: "c", Status: "old", Comment: "", Ignored: ""},
+		}, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), ra)
+}
+
+func TestUpdateStruct(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	querier := NewMockIQuerier(mc)
+	querier.EXPECT().Exec(gomock.Any(),
+		"UPDATE items SET comment = $1, name = $2, status = $3 WHERE deleted = $4 AND id = $5",
+		"", "a", "new", false, int64(1)).
+		Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+
+	tag, err := UpdateStruct(ctx, querier, pgh.Builder().Update("items").Where("deleted = ?", false),
+		structItem{ID: 1, Name: "a", Status: "new", Comment: "", Ignored: "x"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), tag.RowsAffected())
+
+	type noPK struct {
+		Name string `db:"name"`
+	}
+	_, err = UpdateStruct(ctx, querier, pgh.Builder().Update("items"), noPK{Name: "a"})
+	require.ErrorIs(t, err, ErrNoPrimaryKey)
+
+	builder, err := UpdateStructBuilder(pgh.Builder().Update("items"),
+		&structItem{ID: 2, Name: "b", Status: "", Comment: "", Ignored: ""})
+	require.NoError(t, err)
+	sql, args, err := builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE items SET comment = $1, name = $2, status = $3 WHERE id = $4", sql)
+	require.Equal(t, []any{"", "b", "", int64(2)}, args)
+
+	_, err = UpdateStructBuilder[*structItem](pgh.Builder().Update("items"), nil)
+	require.ErrorIs(t, err, structmap.ErrNilPointer)
+}
@@ -0,0 +1,235 @@
+package px
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/n-r-w/pgh/v2"
+	sq "github.com/n-r-w/squirrel"
+)
+
+// Helpers for INSERT ... ON CONFLICT (upsert) queries.
+
+// UpsertInsertedColumn name of the column added to RETURNING by UpsertSplitQuery.
+// It is true if the row was inserted and false if it was updated.
+const UpsertInsertedColumn = "inserted"
+
+var (
+	// ErrUpsertConflictTarget conflict target is invalid: it is required for DO UPDATE,
+	// and columns and constraint can't be used together.
+	ErrUpsertConflictTarget = errors.New("invalid upsert conflict target")
+	// ErrUpsertAction DO UPDATE and DO NOTHING can't be used together.
+	ErrUpsertAction = errors.New("invalid upsert action")
+)
+
+// UpsertOption option for upsert queries.
+type UpsertOption func(*upsertOptions)
+
+type upsertSet struct {
+	column string
+	expr   sq.Sqlizer
+}
+
+type upsertOptions struct {
+	columns    []string
+	constraint string
+	set        []upsertSet
+	doNothing  bool
+	where      []sq.Sqlizer
+	returning  []string
+}
+
+// OnConflictColumns sets the conflict target by columns of a unique index: ON CONFLICT (columns).
+func OnConflictColumns(columns ...string) UpsertOption {
+	return func(o *upsertOptions) {
+		o.columns = append(o.columns, columns...)
+	}
+}
+
+// OnConflictConstraint sets the conflict target by a constraint name: ON CONFLICT ON CONSTRAINT name.
+func OnConflictConstraint(name string) UpsertOption {
+	return func(o *upsertOptions) {
+		o.constraint = name
+	}
+}
+
+// DoUpdate updates columns with the values proposed for insertion: SET column = EXCLUDED.column.
+func DoUpdate(columns ...string) UpsertOption {
+	return func(o *upsertOptions) {
+		for _, column := range columns {
+			o.set = append(o.set, upsertSet{column: column, expr: sq.Expr("EXCLUDED." + column)})
+		}
+	}
+}
+
+// DoUpdateExpr updates column with an expression, e.g. DoUpdateExpr("count", "t.count + EXCLUDED.count").
+// The existing row is referenced by the table name or alias, the proposed row by EXCLUDED.
+func DoUpdateExpr(column, sql string, args ...any) UpsertOption {
+	return func(o *upsertOptions) {
+		o.set = append(o.set, upsertSet{column: column, expr: sq.Expr(sql, args...)})
+	}
+}
+
+// DoNothing skips conflicting rows: ON CONFLICT DO NOTHING. It is used if no columns are updated.
+func DoNothing() UpsertOption {
+	return func(o *upsertOptions) {
+		o.doNothing = true
+	}
+}
+
+// UpdateWhere adds a condition to DO UPDATE. Rows that don't match it are neither updated nor returned.
+// pred is the same as in sq.SelectBuilder.Where.
+func UpdateWhere(pred any, args ...any) UpsertOption {
+	return func(o *upsertOptions) {
+		o.where = append(o.where, whereSqlizer(pred, args...))
+	}
+}
+
+// UpsertReturning sets columns of the RETURNING clause for UpsertSplitQuery.
+func UpsertReturning(columns ...string) UpsertOption {
+	return func(o *upsertOptions) {
+		o.returning = append(o.returning, columns...)
+	}
+}
+
+// UpsertBuilder adds the ON CONFLICT clause to base, which must contain the table, columns and can contain values.
+// RETURNING from UpsertReturning is added after ON CONFLICT.
+func UpsertBuilder(base sq.InsertBuilder, opts ...UpsertOption) (sq.InsertBuilder, error) {
+	//nolint:exhaustruct // filled by options
+	o := upsertOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	clause, args, err := o.onConflict()
+	if err != nil {
+		return base, err
+	}
+
+	base = base.Suffix(clause, args...)
+	if len(o.returning) > 0 {
+		base = base.Suffix("RETURNING " + strings.Join(o.returning, ", "))
+	}
+
+	return base, nil
+}
+
+// UpsertSplit splits rows into groups of splitSize and inserts them with the ON CONFLICT clause
+// within one batch. base must contain the table and columns.
+// values - rows with the same number of values in each. tx can be either pgx.Tx or pg_types.Pool.
+// Rows affected by a single statement must be unique by the conflict target,
+// otherwise Postgres returns an error for DO UPDATE.
+func UpsertSplit(
+	ctx context.Context,
+	tx IBatcher,
+	base sq.InsertBuilder,
+	values []pgh.Args,
+	splitSize int,
+	opts ...UpsertOption,
+) (rowsAffected int64, err error) {
+	query, err := UpsertBuilder(base, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("UpsertSplit: %w", err)
+	}
+
+	return InsertSplit(ctx, tx, query, values, splitSize)
+}
+
+// UpsertSplitQuery is the same as UpsertSplit, but returns columns set by UpsertReturning into dst.
+// The column UpsertInsertedColumn is added to RETURNING: it is true for inserted rows and false for updated ones,
+// so T should contain a field with the `db:"inserted"` tag. Rows skipped by DO NOTHING or UpdateWhere are not returned.
+func UpsertSplitQuery[T any](
+	ctx context.Context,
+	tx IBatcher,
+	base sq.InsertBuilder,
+	values []pgh.Args,
+	splitSize int,
+	dst *[]T,
+	opts ...UpsertOption,
+) error {
+	// xmax is zero for rows inserted by the current transaction and contains its id for updated ones
+	opts = append(opts[:len(opts):len(opts)],
+		UpsertReturning(fmt.Sprintf("(xmax = 0) AS %s", UpsertInsertedColumn)))
+
+	query, err := UpsertBuilder(base, opts...)
+	if err != nil {
+		return fmt.Errorf("UpsertSplitQuery: %w", err)
+	}
+
+	return InsertSplitQuery(ctx, tx, query, values, splitSize, dst)
+}
+
+// onConflict returns the ON CONFLICT clause.
+func (o *upsertOptions) onConflict() (string, []any, error) {
+	if len(o.columns) > 0 && o.constraint != "" {
+		return "", nil, fmt.Errorf("%w: both columns and constraint are set", ErrUpsertConflictTarget)
+	}
+	if len(o.set) > 0 && o.doNothing {
+		return "", nil, fmt.Errorf("%w: both DO UPDATE and DO NOTHING are set", ErrUpsertAction)
+	}
+
+	var sql strings.Builder
+	_, _ = sql.WriteString("ON CONFLICT")
+
+	switch {
+	case len(o.columns) > 0:
+		_, _ = sql.WriteString(" (" + strings.Join(o.columns, ", ") + ")")
+	case o.constraint != "":
+		_, _ = sql.WriteString(" ON CONSTRAINT " + o.constraint)
+	case len(o.set) > 0:
+		return "", nil, fmt.Errorf("%w: DO UPDATE requires columns or constraint", ErrUpsertConflictTarget)
+	}
+
+	if len(o.set) == 0 {
+		if len(o.where) > 0 {
+			return "", nil, fmt.Errorf("%w: UpdateWhere requires DO UPDATE", ErrUpsertAction)
+		}
+
+		_, _ = sql.WriteString(" DO NOTHING")
+		return sql.String(), nil, nil
+	}
+
+	var args []any
+
+	_, _ = sql.WriteString(" DO UPDATE SET ")
+	for i, s := range o.set {
+		exprSQL, exprArgs, err := s.expr.ToSql()
+		if err != nil {
+			return "", nil, err
+		}
+
+		if i > 0 {
+			_, _ = sql.WriteString(", ")
+		}
+		_, _ = sql.WriteString(s.column + " = " + exprSQL)
+		args = append(args, exprArgs...)
+	}
+
+	if len(o.where) > 0 {
+		whereSQL, whereArgs, err := sq.And(o.where).ToSql()
+		if err != nil {
+			return "", nil, err
+		}
+
+		_, _ = sql.WriteString(" WHERE " + whereSQL)
+		args = append(args, whereArgs...)
+	}
+
+	return sql.String(), args, nil
+}
+
+// whereSqlizer converts a predicate in the format of sq.SelectBuilder.Where to sq.Sqlizer.
+func whereSqlizer(pred any, args ...any) sq.Sqlizer {
+	switch p := pred.(type) {
+	case string:
+		return sq.Expr(p, args...)
+	case sq.Sqlizer:
+		return p
+	case map[string]any:
+		return sq.Eq(p)
+	default:
+		return sq.Expr(fmt.Sprint(pred), args...)
+	}
+}
@@ -0,0 +1,120 @@
+package px
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/n-r-w/pgh/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpsertBuilder(t *testing.T) {
+	t.Parallel()
+
+	base := pgh.Builder().Insert("items").Columns("id", "name", "count").Values(1, "a", 2)
+
+	query, err := UpsertBuilder(base,
+		OnConflictColumns("id"),
+		DoUpdate("name"),
+		DoUpdateExpr("count", "items.count + EXCLUDED.count + ?", 10),
+		UpdateWhere("items.deleted = ?", false),
+		UpsertReturning("id", "name"),
+	)
+	require.NoError(t, err)
+
+	sql, args, err := query.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO items (id,name,count) VALUES ($1,$2,$3) "+
+		"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, count = items.count + EXCLUDED.count + $4 "+
+		"WHERE (items.deleted = $5) RETURNING id, name", sql)
+	require.Equal(t, []any{1, "a", 2, 10, false}, args)
+
+	query, err = UpsertBuilder(base, OnConflictConstraint("items_pkey"), DoNothing())
+	require.NoError(t, err)
+	sql, _, err = query.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO items (id,name,count) VALUES ($1,$2,$3) ON CONFLICT ON CONSTRAINT items_pkey DO NOTHING", sql)
+
+	query, err = UpsertBuilder(base)
+	require.NoError(t, err)
+	sql, _, err = query.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO items (id,name,count) VALUES ($1,$2,$3) ON CONFLICT DO NOTHING", sql)
+
+	_, err = UpsertBuilder(base, DoUpdate("name"))
+	require.ErrorIs(t, err, ErrUpsertConflictTarget)
+	_, err = UpsertBuilder(base, OnConflictColumns("id"), OnConflictConstraint("items_pkey"))
+	require.ErrorIs(t, err, ErrUpsertConflictTarget)
+	_, err = UpsertBuilder(base, OnConflictColumns("id"), DoUpdate("name"), DoNothing())
+	require.ErrorIs(t, err, ErrUpsertAction)
+	_, err = UpsertBuilder(base, OnConflictColumns("id"), UpdateWhere("deleted = false"))
+	require.ErrorIs(t, err, ErrUpsertAction)
+}
+
+func TestUpsertSplit(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	batchMock := NewMockIBatcher(mc)
+	batchMock.EXPECT().SendBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, batch *pgx.Batch) pgx.BatchResults {
+			require.Equal(t, 2, batch.Len())
+			require.Equal(t, "INSERT INTO items (id,name) VALUES ($1,$2),($3,$4) "+
+				"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name", batch.QueuedQueries[0].SQL)
+			require.Equal(t, []any{1, "a", 2, "b"}, batch.QueuedQueries[0].Arguments)
+			require.Equal(t, "INSERT INTO items (id,name) VALUES ($1,$2) "+
+				"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name", batch.QueuedQueries[1].SQL)
+
+			batchResultMock := NewMockBatchResults(mc)
+			batchResultMock.EXPECT().Exec().Return(pgconn.NewCommandTag("INSERT 0 2"), nil)
+			batchResultMock.EXPECT().Exec().Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+			batchResultMock.EXPECT().Close().Return(nil)
+
+			return batchResultMock
+		})
+
+	ra, err := UpsertSplit(ctx, batchMock, pgh.Builder().Insert("items").Columns("id", "name"),
+		[]pgh.Args{{1, "a"}, {2, "b"}, {3, "c"}}, 2,
+		OnConflictColumns("id"), DoUpdate("name"))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), ra)
+}
+
+func TestUpsertSplitQuery(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	batchMock := NewMockIBatcher(mc)
+	batchMock.EXPECT().SendBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, batch *pgx.Batch) pgx.BatchResults {
+			require.Equal(t, 1, batch.Len())
+			require.Equal(t, "INSERT INTO items (id,name) VALUES ($1,$2) "+
+				"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name RETURNING id, (xmax = 0) AS inserted",
+				batch.QueuedQueries[0].SQL)
+
+			batchResultMock := NewMockBatchResults(mc)
+			batchResultMock.EXPECT().Query().Return(nil, pgx.ErrTxClosed)
+			batchResultMock.EXPECT().Close().Return(nil)
+
+			return batchResultMock
+		})
+
+	type result struct {
+		ID       int  `db:"id"`
+		Inserted bool `db:"inserted"`
+	}
+	var dst []result
+	err := UpsertSplitQuery(ctx, batchMock, pgh.Builder().Insert("items").Columns("id", "name"),
+		[]pgh.Args{{1, "a"}}, 10, &dst,
+		OnConflictColumns("id"), DoUpdate("name"), UpsertReturning("id"))
+	require.ErrorIs(t, err, pgx.ErrTxClosed)
+}
@@ -0,0 +1,23 @@
+package txmgr
+
+import "errors"
+
+var (
+	// ErrLockNotSupported advisory locks are requested in a nested call, but ITransactionBeginner
+	// doesn't implement ITransactionLocker.
+	ErrLockNotSupported = errors.New("advisory locks are not supported")
+
+	// ErrSavepointNotSupported savepoint is requested in a nested call, but ITransactionBeginner
+	// doesn't implement ITransactionSavepointer.
+	ErrSavepointNotSupported = errors.New("savepoints are not supported")
+
+	// ErrRetryNotSupported retry is requested without RetryClassifier, but ITransactionBeginner
+	// doesn't implement IRetryClassifier.
+	ErrRetryNotSupported = errors.New("retry classifier is not defined")
+
+	// ErrNoTransaction transaction is not started.
+	ErrNoTransaction = errors.New("transaction is not started")
+
+	// ErrLockTimeout advisory lock was not acquired within the lock timeout.
+	ErrLockTimeout = errors.New("advisory lock timeout")
+)
@@ -0,0 +1,52 @@
+package txmgr
+
+import "context"
+
+type hooksKeyType int
+
+// hooksKey key for storing transaction hooks in context.
+const hooksKey hooksKeyType = 0
+
+// ContextWithHooks returns a context with hooks of the current transaction.
+// Used by ITransactionBeginner implementations when putting a transaction in context.
+// If hooks is nil, the returned context has no hooks.
+func ContextWithHooks(ctx context.Context, hooks ITransactionHooks) context.Context {
+	return context.WithValue(ctx, hooksKey, hooks)
+}
+
+// OnCommit registers a function that is called after the transaction stored in ctx is committed.
+// Nested Begin calls share the outer transaction, so the function is called after the outermost commit.
+// Functions are called in registration order with a context without transaction.
+// Returns ErrNoTransaction if ctx has no transaction.
+func OnCommit(ctx context.Context, fn func(ctx context.Context)) error {
+	hooks, ok := hooksFromContext(ctx)
+	if !ok {
+		return ErrNoTransaction
+	}
+
+	hooks.OnCommit(fn)
+	return nil
+}
+
+// OnRollback registers a function that is called after the transaction stored in ctx is rolled back.
+// Functions are called in registration order with a context without transaction.
+// Returns ErrNoTransaction if ctx has no transaction.
+func OnRollback(ctx context.Context, fn func(ctx context.Context)) error {
+	hooks, ok := hooksFromContext(ctx)
+	if !ok {
+		return ErrNoTransaction
+	}
+
+	hooks.OnRollback(fn)
+	return nil
+}
+
+// hooksFromContext extracts transaction hooks from context.
+func hooksFromContext(ctx context.Context) (ITransactionHooks, bool) {
+	hooks, ok := ctx.Value(hooksKey).(ITransactionHooks)
+	if !ok || hooks == nil {
+		return nil, false
+	}
+
+	return hooks, true
+}
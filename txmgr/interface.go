@@ -29,6 +29,40 @@ type ITransactionBeginner interface {
 	WithoutTransaction(ctx context.Context) context.Context
 }
 
+// ITransactionLocker optional interface of ITransactionBeginner for acquiring advisory locks
+// in an already started transaction. Implemented in pgdb package.
+type ITransactionLocker interface {
+	// AcquireLocks acquires advisory locks for opts.LockKeys in the transaction stored in ctx.
+	AcquireLocks(ctx context.Context, opts Options) error
+}
+
+// ITransactionSavepointer optional interface of ITransactionBeginner for nested transactions
+// based on savepoints. Implemented in pgdb package.
+type ITransactionSavepointer interface {
+	// BeginSavepoint runs a function within a savepoint of the transaction stored in ctx.
+	// Rolls back to the savepoint if the function returns an error, otherwise releases it.
+	BeginSavepoint(ctx context.Context, f func(ctxTr context.Context) error, opts Options) error
+	// BeginSavepointTx creates a savepoint in the transaction stored in ctx.
+	// Commit releases the savepoint, Rollback rolls back to it.
+	BeginSavepointTx(ctx context.Context, opts Options) (context.Context, ITransactionFinisher, error)
+}
+
+// IRetryClassifier optional interface of ITransactionBeginner for detecting errors after which
+// the transaction can be retried. Implemented in pgdb package.
+type IRetryClassifier interface {
+	// IsRetryable returns true if the transaction failed with err can be retried.
+	IsRetryable(err error) bool
+}
+
+// ITransactionHooks interface of a transaction that runs callbacks after it is finished.
+// Implemented in pgdb package and put in context with ContextWithHooks.
+type ITransactionHooks interface {
+	// OnCommit registers a function that is called after the transaction is committed.
+	OnCommit(fn func(ctx context.Context))
+	// OnRollback registers a function that is called after the transaction is rolled back.
+	OnRollback(fn func(ctx context.Context))
+}
+
 // ITransactionManager interface for managing database transactions.
 // Located here at the implementation point for convenient use in other packages.
 type ITransactionManager interface {
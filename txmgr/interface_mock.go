@@ -185,6 +185,180 @@ func (mr *MockITransactionBeginnerMockRecorder) WithoutTransaction(ctx any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithoutTransaction", reflect.TypeOf((*MockITransactionBeginner)(nil).WithoutTransaction), ctx)
 }
 
+// MockITransactionLocker is a mock of ITransactionLocker interface.
+type MockITransactionLocker struct {
+	ctrl     *gomock.Controller
+	recorder *MockITransactionLockerMockRecorder
+}
+
+// MockITransactionLockerMockRecorder is the mock recorder for MockITransactionLocker.
+type MockITransactionLockerMockRecorder struct {
+	mock *MockITransactionLocker
+}
+
+// NewMockITransactionLocker creates a new mock instance.
+func NewMockITransactionLocker(ctrl *gomock.Controller) *MockITransactionLocker {
+	mock := &MockITransactionLocker{ctrl: ctrl}
+	mock.recorder = &MockITransactionLockerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockITransactionLocker) EXPECT() *MockITransactionLockerMockRecorder {
+	return m.recorder
+}
+
+// AcquireLocks mocks base method.
+func (m *MockITransactionLocker) AcquireLocks(ctx context.Context, opts Options) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireLocks", ctx, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcquireLocks indicates an expected call of AcquireLocks.
+func (mr *MockITransactionLockerMockRecorder) AcquireLocks(ctx, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireLocks", reflect.TypeOf((*MockITransactionLocker)(nil).AcquireLocks), ctx, opts)
+}
+
+// MockITransactionSavepointer is a mock of ITransactionSavepointer interface.
+type MockITransactionSavepointer struct {
+	ctrl     *gomock.Controller
+	recorder *MockITransactionSavepointerMockRecorder
+}
+
+// MockITransactionSavepointerMockRecorder is the mock recorder for MockITransactionSavepointer.
+type MockITransactionSavepointerMockRecorder struct {
+	mock *MockITransactionSavepointer
+}
+
+// NewMockITransactionSavepointer creates a new mock instance.
+func NewMockITransactionSavepointer(ctrl *gomock.Controller) *MockITransactionSavepointer {
+	mock := &MockITransactionSavepointer{ctrl: ctrl}
+	mock.recorder = &MockITransactionSavepointerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockITransactionSavepointer) EXPECT() *MockITransactionSavepointerMockRecorder {
+	return m.recorder
+}
+
+// BeginSavepoint mocks base method.
+func (m *MockITransactionSavepointer) BeginSavepoint(ctx context.Context, f func(context.Context) error, opts Options) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginSavepoint", ctx, f, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeginSavepoint indicates an expected call of BeginSavepoint.
+func (mr *MockITransactionSavepointerMockRecorder) BeginSavepoint(ctx, f, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginSavepoint", reflect.TypeOf((*MockITransactionSavepointer)(nil).BeginSavepoint), ctx, f, opts)
+}
+
+// BeginSavepointTx mocks base method.
+func (m *MockITransactionSavepointer) BeginSavepointTx(ctx context.Context, opts Options) (context.Context, ITransactionFinisher, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginSavepointTx", ctx, opts)
+	ret0, _ := ret[0].(context.Context)
+	ret1, _ := ret[1].(ITransactionFinisher)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BeginSavepointTx indicates an expected call of BeginSavepointTx.
+func (mr *MockITransactionSavepointerMockRecorder) BeginSavepointTx(ctx, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginSavepointTx", reflect.TypeOf((*MockITransactionSavepointer)(nil).BeginSavepointTx), ctx, opts)
+}
+
+// MockIRetryClassifier is a mock of IRetryClassifier interface.
+type MockIRetryClassifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockIRetryClassifierMockRecorder
+}
+
+// MockIRetryClassifierMockRecorder is the mock recorder for MockIRetryClassifier.
+type MockIRetryClassifierMockRecorder struct {
+	mock *MockIRetryClassifier
+}
+
+// NewMockIRetryClassifier creates a new mock instance.
+func NewMockIRetryClassifier(ctrl *gomock.Controller) *MockIRetryClassifier {
+	mock := &MockIRetryClassifier{ctrl: ctrl}
+	mock.recorder = &MockIRetryClassifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIRetryClassifier) EXPECT() *MockIRetryClassifierMockRecorder {
+	return m.recorder
+}
+
+// IsRetryable mocks base method.
+func (m *MockIRetryClassifier) IsRetryable(err error) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRetryable", err)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsRetryable indicates an expected call of IsRetryable.
+func (mr *MockIRetryClassifierMockRecorder) IsRetryable(err any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRetryable", reflect.TypeOf((*MockIRetryClassifier)(nil).IsRetryable), err)
+}
+
+// MockITransactionHooks is a mock of ITransactionHooks interface.
+type MockITransactionHooks struct {
+	ctrl     *gomock.Controller
+	recorder *MockITransactionHooksMockRecorder
+}
+
+// MockITransactionHooksMockRecorder is the mock recorder for MockITransactionHooks.
+type MockITransactionHooksMockRecorder struct {
+	mock *MockITransactionHooks
+}
+
+// NewMockITransactionHooks creates a new mock instance.
+func NewMockITransactionHooks(ctrl *gomock.Controller) *MockITransactionHooks {
+	mock := &MockITransactionHooks{ctrl: ctrl}
+	mock.recorder = &MockITransactionHooksMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockITransactionHooks) EXPECT() *MockITransactionHooksMockRecorder {
+	return m.recorder
+}
+
+// OnCommit mocks base method.
+func (m *MockITransactionHooks) OnCommit(fn func(context.Context)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnCommit", fn)
+}
+
+// OnCommit indicates an expected call of OnCommit.
+func (mr *MockITransactionHooksMockRecorder) OnCommit(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnCommit", reflect.TypeOf((*MockITransactionHooks)(nil).OnCommit), fn)
+}
+
+// OnRollback mocks base method.
+func (m *MockITransactionHooks) OnRollback(fn func(context.Context)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnRollback", fn)
+}
+
+// OnRollback indicates an expected call of OnRollback.
+func (mr *MockITransactionHooksMockRecorder) OnRollback(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnRollback", reflect.TypeOf((*MockITransactionHooks)(nil).OnRollback), fn)
+}
+
 // MockITransactionManager is a mock of ITransactionManager interface.
 type MockITransactionManager struct {
 	ctrl     *gomock.Controller
@@ -3,7 +3,13 @@ package txmgr
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
 )
 
 // Options represents transaction manager configuration options.
@@ -16,6 +22,28 @@ type Options struct {
 	// This is an advisory option and the implementation decides what to lock.
 	// In most cases it means SELECT ... FOR UPDATE.
 	Lock bool
+	// LockKeys contains keys of advisory locks that must be held until the end of the transaction.
+	// Nested Begin/BeginTx calls with other keys acquire them in the already started transaction.
+	LockKeys []int64
+	// LockTimeout limits the time spent waiting for advisory locks. Zero means waiting without limit.
+	LockTimeout time.Duration
+	// StatementTimeout limits the execution time of each statement of the transaction on the server.
+	// Zero means the server default.
+	StatementTimeout time.Duration
+	// ServerLockTimeout limits the time each statement of the transaction waits for any lock on the server.
+	// Zero means the server default.
+	ServerLockTimeout time.Duration
+	// Savepoint indicates that a nested call must run within a savepoint of the already started transaction.
+	// Has no effect if the transaction is not started yet.
+	Savepoint bool
+	// Retry indicates that the whole function must be re-run in a new transaction on retryable errors.
+	// Works only for Begin at the outermost level.
+	Retry bool
+	// RetryOptions contains backoff policy for retries.
+	RetryOptions []backoff.RetryOption
+	// RetryClassifier returns true if the error is retryable.
+	// If nil, IRetryClassifier implementation of ITransactionBeginner is used.
+	RetryClassifier func(err error) bool
 }
 
 // Option transaction manager option function.
@@ -35,13 +63,80 @@ func WithTransactionMode(mode TransactionMode) Option {
 	}
 }
 
-// WithLock enables object locking.
-func WithLock() Option {
+// WithLock enables object locking. If keys are provided, transaction-level advisory locks
+// are acquired for them. Use LockKey to get a key from a string.
+func WithLock(keys ...int64) Option {
 	return func(opts *Options) {
 		opts.Lock = true
+		opts.LockKeys = append(opts.LockKeys, keys...)
+	}
+}
+
+// WithLockTimeout sets the maximum time to wait for advisory locks.
+// If the locks are not acquired in time, ErrLockTimeout is returned.
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.LockTimeout = timeout
 	}
 }
 
+// WithStatementTimeout sets the maximum execution time of each statement of the transaction.
+// The implementation applies it on the server, e.g. with SET LOCAL statement_timeout for PostgreSQL.
+// Has no effect for nested calls within an already started transaction.
+func WithStatementTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.StatementTimeout = timeout
+	}
+}
+
+// WithServerLockTimeout sets the maximum time each statement of the transaction waits for row, table
+// and other locks, e.g. with SET LOCAL lock_timeout for PostgreSQL. Unlike WithLockTimeout, it is applied
+// by the server to every statement. Has no effect for nested calls within an already started transaction.
+func WithServerLockTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.ServerLockTimeout = timeout
+	}
+}
+
+// WithSavepoint enables savepoint-based nesting. If a transaction is already started, the function
+// runs within a savepoint: it is rolled back to the savepoint on error and released on success,
+// so the error can be handled without aborting the outer transaction.
+func WithSavepoint() Option {
+	return func(opts *Options) {
+		opts.Savepoint = true
+	}
+}
+
+// DefaultRetryMaxTries default maximum number of attempts of WithRetry. Override it with backoff.WithMaxTries.
+const DefaultRetryMaxTries = 5
+
+// WithRetry enables re-running the whole function in a new transaction on retryable errors,
+// e.g. serialization failures and deadlocks. Retries are performed only by Begin at the outermost level
+// and are suppressed when the function runs inside an already started transaction.
+// Without options, the default exponential backoff policy of github.com/cenkalti/backoff/v5 is used,
+// limited to DefaultRetryMaxTries attempts. If ctx is canceled while waiting for a retry,
+// the context error is returned together with the last error of the transaction.
+func WithRetry(policy ...backoff.RetryOption) Option {
+	return func(opts *Options) {
+		opts.Retry = true
+		opts.RetryOptions = append(opts.RetryOptions, policy...)
+	}
+}
+
+// WithRetryClassifier sets a function that decides if an error is retryable.
+func WithRetryClassifier(classifier func(err error) bool) Option {
+	return func(opts *Options) {
+		opts.RetryClassifier = classifier
+	}
+}
+
+// LockKey returns an advisory lock key for a string, for example "order:42".
+func LockKey(key string) int64 {
+	h := fnv.New64a()
+	_, _ = io.WriteString(h, key)
+	return int64(h.Sum64()) //nolint:gosec // overflow is expected, any int64 is a valid key
+}
+
 // TransactionManager handles database transactions.
 type TransactionManager struct {
 	tmBeginner      ITransactionBeginner
@@ -59,9 +154,17 @@ func New(tmBeginner ITransactionBeginner, tmImplementator ITransactionInformer)
 func (tm *TransactionManager) prepareBegin(ctx context.Context, opts []Option) (*Options, error) {
 	// get options
 	tmOpts := &Options{
-		Level: TxLevelDefault,
-		Mode:  TxModeDefault,
-		Lock:  false,
+		Level:             TxLevelDefault,
+		Mode:              TxModeDefault,
+		Lock:              false,
+		LockKeys:          nil,
+		LockTimeout:       0,
+		StatementTimeout:  0,
+		ServerLockTimeout: 0,
+		Savepoint:         false,
+		Retry:             false,
+		RetryOptions:      nil,
+		RetryClassifier:   nil,
 	}
 	for _, opt := range opts {
 		opt(tmOpts)
@@ -90,15 +193,68 @@ func (tm *TransactionManager) Begin(ctx context.Context, f func(ctxTr context.Co
 	}
 
 	if tm.tmImplementator.InTransaction(ctx) { // transaction is already started
+		if err := tm.acquireLocks(ctx, tmOpts); err != nil {
+			return err
+		}
+
+		if tmOpts.Savepoint {
+			savepointer, ok := tm.tmBeginner.(ITransactionSavepointer)
+			if !ok {
+				return ErrSavepointNotSupported
+			}
+
+			return savepointer.BeginSavepoint(ctx, f, *tmOpts)
+		}
+
 		// just execute the function
 		return f(ctx)
 	}
 
 	// transaction is not started yet
+	if tmOpts.Retry {
+		return tm.beginWithRetry(ctx, f, tmOpts)
+	}
+
 	return tm.tmBeginner.Begin(ctx, f, *tmOpts)
 }
 
-// BeginTx starts a new transaction.
+// beginWithRetry starts a new transaction and re-runs it on retryable errors.
+func (tm *TransactionManager) beginWithRetry(
+	ctx context.Context, f func(ctxTr context.Context) error, tmOpts *Options,
+) error {
+	isRetryable := tmOpts.RetryClassifier
+	if isRetryable == nil {
+		classifier, ok := tm.tmBeginner.(IRetryClassifier)
+		if !ok {
+			return ErrRetryNotSupported
+		}
+		isRetryable = classifier.IsRetryable
+	}
+
+	var lastErr error
+	_, err := backoff.Retry(ctx, func() (struct{}, error) {
+		lastErr = tm.tmBeginner.Begin(ctx, f, *tmOpts)
+		if lastErr != nil && !isRetryable(lastErr) {
+			return struct{}{}, backoff.Permanent(lastErr)
+		}
+		return struct{}{}, lastErr
+	}, append([]backoff.RetryOption{backoff.WithMaxTries(DefaultRetryMaxTries)}, tmOpts.RetryOptions...)...)
+
+	// backoff returns a permanent error as is when the maximum number of tries is reached
+	var permanent *backoff.PermanentError
+	if errors.As(err, &permanent) {
+		return permanent.Unwrap()
+	}
+
+	// backoff returns only the context error if ctx is canceled between attempts
+	if err != nil && lastErr != nil && !errors.Is(err, lastErr) {
+		return fmt.Errorf("%w: %w", err, lastErr)
+	}
+
+	return err
+}
+
+// BeginTx starts a new transaction. Retry options are ignored, because the transaction is finished by the caller.
 func (tm *TransactionManager) BeginTx(
 	ctx context.Context, opts ...Option,
 ) (context.Context, ITransactionFinisher, error) {
@@ -108,12 +264,39 @@ func (tm *TransactionManager) BeginTx(
 	}
 
 	if tm.tmImplementator.InTransaction(ctx) { // transaction is already started
+		if err := tm.acquireLocks(ctx, tmOpts); err != nil {
+			return nil, nil, err
+		}
+
+		if tmOpts.Savepoint {
+			savepointer, ok := tm.tmBeginner.(ITransactionSavepointer)
+			if !ok {
+				return nil, nil, ErrSavepointNotSupported
+			}
+
+			return savepointer.BeginSavepointTx(ctx, *tmOpts)
+		}
+
 		return ctx, &noopTransactionFinisher{}, nil
 	}
 
 	return tm.tmBeginner.BeginTx(ctx, *tmOpts)
 }
 
+// acquireLocks acquires advisory locks requested by a nested call in the already started transaction.
+func (tm *TransactionManager) acquireLocks(ctx context.Context, tmOpts *Options) error {
+	if len(tmOpts.LockKeys) == 0 {
+		return nil
+	}
+
+	locker, ok := tm.tmBeginner.(ITransactionLocker)
+	if !ok {
+		return ErrLockNotSupported
+	}
+
+	return locker.AcquireLocks(ctx, *tmOpts)
+}
+
 // WithoutTransaction returns context without transaction.
 func (tm *TransactionManager) WithoutTransaction(ctx context.Context) context.Context {
 	return tm.tmBeginner.WithoutTransaction(ctx)
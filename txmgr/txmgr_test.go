@@ -2,8 +2,11 @@ package txmgr
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/cenkalti/backoff/v5"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -25,6 +28,8 @@ func TestTransactionManager_Begin(t *testing.T) {
 			require.Equal(t, TxReadCommitted, opts.Level)
 			require.Equal(t, TxReadWrite, opts.Mode)
 			require.True(t, opts.Lock)
+			require.Equal(t, 5*time.Second, opts.StatementTimeout)
+			require.Equal(t, time.Second, opts.ServerLockTimeout)
 			return f(ctx)
 		}).
 		Return(nil)
@@ -36,7 +41,8 @@ func TestTransactionManager_Begin(t *testing.T) {
 
 	require.NoError(t, tm.Begin(ctx, func(_ context.Context) error {
 		return nil
-	}, WithTransactionLevel(TxReadCommitted), WithTransactionMode(TxReadWrite), WithLock()))
+	}, WithTransactionLevel(TxReadCommitted), WithTransactionMode(TxReadWrite), WithLock(),
+		WithStatementTimeout(5*time.Second), WithServerLockTimeout(time.Second)))
 }
 
 // TestTransactionManager_Begin_InTransaction tests transaction start when a transaction is already in progress.
@@ -74,3 +80,250 @@ func TestTransactionManager_Begin_InTransaction(t *testing.T) {
 		return nil
 	}, WithTransactionMode(TxReadOnly)))
 }
+
+// lockingBeginner is a transaction beginner that supports advisory locks.
+type lockingBeginner struct {
+	*MockITransactionBeginner
+	*MockITransactionLocker
+}
+
+// TestTransactionManager_Begin_NestedLock tests acquiring advisory locks in an already started transaction.
+func TestTransactionManager_Begin_NestedLock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	tmBeginner := NewMockITransactionBeginner(mc)
+	tmBeginner.EXPECT().Begin(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	tmLocker := NewMockITransactionLocker(mc)
+	tmLocker.EXPECT().AcquireLocks(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, opts Options) error {
+			require.Equal(t, []int64{1, LockKey("order:42")}, opts.LockKeys)
+			require.Equal(t, time.Second, opts.LockTimeout)
+			return nil
+		}).
+		Return(nil)
+
+	tmInformer := NewMockITransactionInformer(mc)
+	tmInformer.EXPECT().InTransaction(gomock.Any()).Return(true).Times(4)
+	//nolint:exhaustruct // internal type, zero values are acceptable defaults for test
+	tmInformer.EXPECT().TransactionOptions(gomock.Any()).Return(Options{}).Times(2)
+
+	tm := New(&lockingBeginner{MockITransactionBeginner: tmBeginner, MockITransactionLocker: tmLocker}, tmInformer)
+
+	called := false
+	require.NoError(t, tm.Begin(ctx, func(_ context.Context) error {
+		called = true
+		return nil
+	}, WithLock(1, LockKey("order:42")), WithLockTimeout(time.Second)))
+	require.True(t, called)
+
+	// beginner without ITransactionLocker can't acquire locks in a nested call
+	tm = New(tmBeginner, tmInformer)
+	require.ErrorIs(t, tm.Begin(ctx, func(_ context.Context) error {
+		return nil
+	}, WithLock(1)), ErrLockNotSupported)
+}
+
+// savepointBeginner is a transaction beginner that supports savepoints.
+type savepointBeginner struct {
+	*MockITransactionBeginner
+	*MockITransactionSavepointer
+}
+
+// TestTransactionManager_Begin_Savepoint tests savepoint-based nesting.
+func TestTransactionManager_Begin_Savepoint(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	errInner := errors.New("inner error")
+
+	tmBeginner := NewMockITransactionBeginner(mc)
+	tmBeginner.EXPECT().Begin(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	tmSavepointer := NewMockITransactionSavepointer(mc)
+	tmSavepointer.EXPECT().BeginSavepoint(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, f func(context.Context) error, opts Options) error {
+			require.True(t, opts.Savepoint)
+			return f(ctx)
+		})
+	tmSavepointer.EXPECT().BeginSavepointTx(gomock.Any(), gomock.Any()).
+		Return(ctx, &noopTransactionFinisher{}, nil)
+
+	tmInformer := NewMockITransactionInformer(mc)
+	tmInformer.EXPECT().InTransaction(gomock.Any()).Return(true).Times(6)
+	//nolint:exhaustruct // internal type, zero values are acceptable defaults for test
+	tmInformer.EXPECT().TransactionOptions(gomock.Any()).Return(Options{}).Times(3)
+
+	tm := New(&savepointBeginner{MockITransactionBeginner: tmBeginner, MockITransactionSavepointer: tmSavepointer},
+		tmInformer)
+
+	require.ErrorIs(t, tm.Begin(ctx, func(_ context.Context) error {
+		return errInner
+	}, WithSavepoint()), errInner)
+
+	_, finisher, err := tm.BeginTx(ctx, WithSavepoint())
+	require.NoError(t, err)
+	require.NoError(t, finisher.Commit(ctx))
+
+	// beginner without ITransactionSavepointer can't create savepoints
+	tm = New(tmBeginner, tmInformer)
+	require.ErrorIs(t, tm.Begin(ctx, func(_ context.Context) error {
+		return nil
+	}, WithSavepoint()), ErrSavepointNotSupported)
+}
+
+// retryBeginner is a transaction beginner that classifies retryable errors.
+type retryBeginner struct {
+	*MockITransactionBeginner
+	*MockIRetryClassifier
+}
+
+// TestTransactionManager_Begin_Retry tests retrying of the whole transaction.
+func TestTransactionManager_Begin_Retry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	var (
+		errRetryable = errors.New("serialization failure")
+		errFatal     = errors.New("fatal")
+		policy       = WithRetry(backoff.WithBackOff(&backoff.ZeroBackOff{}), backoff.WithMaxTries(5))
+	)
+
+	tmInformer := NewMockITransactionInformer(mc)
+	tmInformer.EXPECT().InTransaction(gomock.Any()).Return(false).AnyTimes()
+
+	// retryable errors are retried until success
+	calls := 0
+	tmBeginner := NewMockITransactionBeginner(mc)
+	tmBeginner.EXPECT().Begin(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, f func(context.Context) error, _ Options) error {
+			return f(ctx)
+		}).Times(3)
+
+	tm := New(tmBeginner, tmInformer)
+	require.NoError(t, tm.Begin(ctx, func(_ context.Context) error {
+		calls++
+		if calls < 3 {
+			return errRetryable
+		}
+		return nil
+	}, policy, WithRetryClassifier(func(err error) bool {
+		return errors.Is(err, errRetryable)
+	})))
+	require.Equal(t, 3, calls)
+
+	// classifier of the beginner is used by default, non-retryable errors are returned immediately
+	tmBeginner = NewMockITransactionBeginner(mc)
+	tmBeginner.EXPECT().Begin(gomock.Any(), gomock.Any(), gomock.Any()).Return(errFatal).Times(1)
+	tmClassifier := NewMockIRetryClassifier(mc)
+	tmClassifier.EXPECT().IsRetryable(errFatal).Return(false).Times(1)
+
+	tm = New(&retryBeginner{MockITransactionBeginner: tmBeginner, MockIRetryClassifier: tmClassifier}, tmInformer)
+	require.ErrorIs(t, tm.Begin(ctx, func(_ context.Context) error {
+		return nil
+	}, policy), errFatal)
+
+	// the last error is returned when tries are exhausted
+	tmBeginner = NewMockITransactionBeginner(mc)
+	tmBeginner.EXPECT().Begin(gomock.Any(), gomock.Any(), gomock.Any()).Return(errRetryable).Times(5)
+	tmClassifier = NewMockIRetryClassifier(mc)
+	tmClassifier.EXPECT().IsRetryable(errRetryable).Return(true).Times(5)
+
+	tm = New(&retryBeginner{MockITransactionBeginner: tmBeginner, MockIRetryClassifier: tmClassifier}, tmInformer)
+	require.ErrorIs(t, tm.Begin(ctx, func(_ context.Context) error {
+		return nil
+	}, policy), errRetryable)
+
+	// beginner without IRetryClassifier requires explicit classifier
+	tm = New(NewMockITransactionBeginner(mc), tmInformer)
+	require.ErrorIs(t, tm.Begin(ctx, func(_ context.Context) error {
+		return nil
+	}, policy), ErrRetryNotSupported)
+}
+
+// TestTransactionManager_Begin_RetryLimits tests the default number of attempts and context cancellation.
+func TestTransactionManager_Begin_RetryLimits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	errRetryable := errors.New("serialization failure")
+	classifier := WithRetryClassifier(func(err error) bool {
+		return errors.Is(err, errRetryable)
+	})
+
+	tmInformer := NewMockITransactionInformer(mc)
+	tmInformer.EXPECT().InTransaction(gomock.Any()).Return(false).AnyTimes()
+
+	// default maximum number of attempts
+	tmBeginner := NewMockITransactionBeginner(mc)
+	tmBeginner.EXPECT().Begin(gomock.Any(), gomock.Any(), gomock.Any()).Return(errRetryable).Times(DefaultRetryMaxTries)
+
+	tm := New(tmBeginner, tmInformer)
+	require.ErrorIs(t, tm.Begin(ctx, func(_ context.Context) error {
+		return nil
+	}, WithRetry(backoff.WithBackOff(&backoff.ZeroBackOff{})), classifier), errRetryable)
+
+	// canceled context keeps the last error
+	ctxCancel, cancel := context.WithCancel(ctx)
+	tmBeginner = NewMockITransactionBeginner(mc)
+	tmBeginner.EXPECT().Begin(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, func(context.Context) error, Options) error {
+			cancel()
+			return errRetryable
+		}).Times(1)
+
+	tm = New(tmBeginner, tmInformer)
+	err := tm.Begin(ctxCancel, func(_ context.Context) error {
+		return nil
+	}, WithRetry(backoff.WithBackOff(&backoff.ZeroBackOff{})), classifier)
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, err, errRetryable)
+}
+
+// TestTransactionManager_Begin_RetryInTransaction tests that retries are suppressed in an outer transaction.
+func TestTransactionManager_Begin_RetryInTransaction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mc := gomock.NewController(t)
+	defer mc.Finish()
+
+	errRetryable := errors.New("serialization failure")
+
+	tmBeginner := NewMockITransactionBeginner(mc)
+	tmBeginner.EXPECT().Begin(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	tmInformer := NewMockITransactionInformer(mc)
+	tmInformer.EXPECT().InTransaction(gomock.Any()).Return(true).Times(2)
+	//nolint:exhaustruct // internal type, zero values are acceptable defaults for test
+	tmInformer.EXPECT().TransactionOptions(gomock.Any()).Return(Options{}).Times(1)
+
+	tm := New(tmBeginner, tmInformer)
+
+	calls := 0
+	require.ErrorIs(t, tm.Begin(ctx, func(_ context.Context) error {
+		calls++
+		return errRetryable
+	}, WithRetry(backoff.WithBackOff(&backoff.ZeroBackOff{})), WithRetryClassifier(func(error) bool {
+		return true
+	})), errRetryable)
+	require.Equal(t, 1, calls)
+}